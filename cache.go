@@ -3,7 +3,6 @@ package sqlca
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/civet148/gotools/log"
 	"github.com/civet148/redigogo"
 	_ "github.com/civet148/redigogo/alone"
 	_ "github.com/civet148/redigogo/cluster"
@@ -109,7 +108,7 @@ func (e *Engine) makeCacheData() (kv *cacheKeyValue) {
 		e.getSingleQuote(), e.getPkValue(), e.getSingleQuote())
 
 	if err != nil {
-		log.Errorf("%s", err)
+		e.errorf("%s", err)
 		return
 	}
 
@@ -140,7 +139,7 @@ func (e *Engine) makeCacheIndexes() (kvs []*cacheKeyValue) {
 			e.getForwardQuote(), e.GetPkName(), e.getBackQuote(), e.getTableName(),
 			e.getForwardQuote(), v.Name, e.getBackQuote(), e.getSingleQuote(), v.Value, e.getSingleQuote())
 		if err != nil {
-			log.Errorf("%s", err)
+			e.errorf("%s", err)
 			return
 		}
 
@@ -179,12 +178,12 @@ func (e *Engine) makeCacheIndexes() (kvs []*cacheKeyValue) {
 func (e *Engine) makeUpdateCacheKv() (kvs []*cacheKeyValue) {
 
 	if !e.getUseCache() {
-		log.Debugf("use cache is disabled, ignore it")
+		e.debugf("use cache is disabled, ignore it")
 		return
 	}
 
 	if isNilOrFalse(e.getPkValue()) {
-		log.Warnf("primary key's value is nil")
+		e.warnf("primary key's value is nil")
 		return
 	}
 	kvs = append(kvs, e.makeCacheData())
@@ -196,7 +195,8 @@ func (e *Engine) saveToCache(kvs ...*cacheKeyValue) (ok bool) {
 	for _, v := range kvs {
 		data, _ := json.Marshal(v.Value)
 		if _, err := e.cache.Do("SETEX", v.Key, e.expireTime, string(data)); err != nil {
-			log.Errorf("set key [%v] value [%v] error [%v]", v.Key, string(data), err.Error())
+			e.incCacheError()
+			e.errorf("set key [%v] value [%v] error [%v]", v.Key, string(data), err.Error())
 			return false
 		}
 	}
@@ -215,13 +215,13 @@ func (e *Engine) upsertCache(lastInsertId int64) {
 	}
 
 	if !e.getUseCache() {
-		log.Debugf("use cache is disabled, ignore it")
+		e.debugf("use cache is disabled, ignore it")
 		return
 	}
 
 	if e.isPkInteger() {
 		if lastInsertId == 0 {
-			log.Errorf("got last insert id is 0")
+			e.errorf("got last insert id is 0")
 			return
 		}
 		e.setPkValue(lastInsertId)
@@ -249,9 +249,10 @@ func (e *Engine) deleteCache() {
 	for _, v := range kvs {
 
 		if _, err := e.cache.Do("DEL", v.Key); err != nil {
-			log.Errorf("DEL key [%v] from redis error [%v]", v.Key, err.Error())
+			e.incCacheError()
+			e.errorf("DEL key [%v] from redis error [%v]", v.Key, err.Error())
 		} else {
-			log.Debugf("DEL key [%v] from redis [OK]", v.Key)
+			e.debugf("DEL key [%v] from redis [OK]", v.Key)
 		}
 	}
 }
@@ -259,30 +260,37 @@ func (e *Engine) deleteCache() {
 func (e *Engine) queryCache() (count int64, ok bool) {
 
 	if e.isCacheNil() {
-		log.Warnf("cache instance is nil, can't update to cache")
+		e.warnf("cache instance is nil, can't update to cache")
 		return
 	}
 
 	if !e.getUseCache() {
-		log.Warnf("use cache is disabled, ignore it")
+		e.warnf("use cache is disabled, ignore it")
 		return
 	}
 
 	if e.isPkValueNil() && len(e.getIndexes()) == 0 {
-		log.Warnf("query condition primary key's value and index not set")
+		e.warnf("query condition primary key's value and index not set")
 		return
 	}
 
 	if e.getOrderBy() != "" || e.getAscAndDesc() != "" || e.getGroupBy() != "" || e.getLimit() != "" || e.getOffset() != "" {
 
-		log.Warnf("query from cache can't use ORDER BY/GROUP BY/LIMIT/OFFSET key words")
+		e.warnf("query from cache can't use ORDER BY/GROUP BY/LIMIT/OFFSET key words")
 		return 0, false
 	}
 
 	if e.isPkValueNil() {
-		return e.queryCacheByIndex()
+		count, ok = e.queryCacheByIndex()
+	} else {
+		count, ok = e.queryCacheById()
+	}
+	if ok {
+		e.incCacheHit()
+	} else {
+		e.incCacheMiss()
 	}
-	return e.queryCacheById()
+	return
 }
 
 func (e *Engine) getCacheValue(strKey string) (kv *cacheKeyValue, ok bool) {
@@ -293,10 +301,10 @@ func (e *Engine) getCacheValue(strKey string) (kv *cacheKeyValue, ok bool) {
 	var reply interface{}
 	reply, err = e.cache.String(e.cache.Do("GET", strKey))
 	if err = e.cache.Unmarshal(&kv.Value, reply, err); err != nil {
-		log.Warnf("cache GET key [%v] error %v", strKey, err.Error())
+		e.warnf("cache GET key [%v] error %v", strKey, err.Error())
 		return kv, false
 	}
-	log.Debugf("cache GET key [%v] value %+v", strKey, kv.Value)
+	e.debugf("cache GET key [%v] value %+v", strKey, kv.Value)
 	return kv, true
 }
 
@@ -311,7 +319,7 @@ func (e *Engine) queryCacheById() (count int64, ok bool) {
 
 	fetchers := e.makeCacheFetcher(kv)
 	if len(fetchers) == 0 {
-		log.Warnf("nothing found by id %+v value [%v] in cache", e.GetPkName(), e.getPkValue())
+		e.warnf("nothing found by id %+v value [%v] in cache", e.GetPkName(), e.getPkValue())
 		return 0, false
 	}
 
@@ -327,20 +335,20 @@ func (e *Engine) queryCacheByIndex() (count int64, ok bool) {
 		strKey := e.makeCacheKey(v.Name, v.Value)
 
 		if kv, ok = e.getCacheValue(strKey); !ok {
-			log.Warnf("cache index GET [%v] value nil", strKey)
+			e.warnf("cache index GET [%v] value nil", strKey)
 			return
 		}
 
 		var cacheIdx cacheIndex
 		if err := json.Unmarshal([]byte(kv.Value.Data), &cacheIdx.Keys); err != nil {
-			log.Errorf("unmarshal [%v] to cache index error [%v]", kv.Value.Data, err.Error())
+			e.errorf("unmarshal [%v] to cache index error [%v]", kv.Value.Data, err.Error())
 			return
 		}
 
 		for _, vv := range cacheIdx.Keys {
 			//log.Debugf("cache GET [%v] ready", vv)
 			if kv, ok = e.getCacheValue(vv); !ok {
-				log.Warnf("cache index GET [%v] value nil", vv)
+				e.warnf("cache index GET [%v] value nil", vv)
 				return 0, false
 			}
 			f := e.makeCacheFetcher(kv)
@@ -349,7 +357,7 @@ func (e *Engine) queryCacheByIndex() (count int64, ok bool) {
 	}
 
 	if len(fetchers) == 0 {
-		log.Warnf("nothing found by index %+v in cache", e.getIndexes())
+		e.warnf("nothing found by index %+v in cache", e.getIndexes())
 		return 0, false
 	}
 
@@ -362,12 +370,12 @@ func (e *Engine) assignCacheToModel(fetchers []*Fetcher) (count int64, ok bool)
 	//log.Debugf("assign cache data to fetchers")
 	if e.getModelType() == ModelType_BaseType {
 		if count, err = e.fetchCache(fetchers, e.model.([]interface{})...); err != nil {
-			log.Errorf("fetchRow error [%v]", err.Error())
+			e.errorf("fetchRow error [%v]", err.Error())
 			return 0, false
 		}
 	} else {
 		if count, err = e.fetchCache(fetchers, e.model); err != nil {
-			log.Errorf("fetchRow error [%v]", err.Error())
+			e.errorf("fetchRow error [%v]", err.Error())
 			return 0, false
 		}
 	}
@@ -380,7 +388,7 @@ func (e *Engine) makeCacheFetcher(kvs ...*cacheKeyValue) (fetchers []*Fetcher) {
 	for _, v := range kvs {
 		var mapValues []map[string]string
 		if err := json.Unmarshal([]byte(v.Value.Data), &mapValues); err != nil {
-			log.Errorf("cache value [%v] unmarshal to map[string]string error [%+v]", v.Value, err.Error())
+			e.errorf("cache value [%v] unmarshal to map[string]string error [%+v]", v.Value, err.Error())
 			continue
 		}
 
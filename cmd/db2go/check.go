@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/civet148/gotools/log"
+	"github.com/civet148/sqlca"
+	"github.com/civet148/sqlca/cmd/db2go/config"
+	"github.com/civet148/sqlca/cmd/db2go/ddl"
+	"github.com/civet148/sqlca/cmd/db2go/mysql"
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+// db2go check --url <url> --out <dir> [--db test] [--package model] [generate flags...] or db2go check --config db2go.toml
+// regenerate go structures in memory and compare with existing files, exit with code 1 if any table or column drifted
+func runCheck() {
+
+	var commanders []*schema.Commander
+	if *argvConfig != "" {
+		cfg, err := config.Load(*argvConfig)
+		if err != nil {
+			log.Errorf("load config [%v] error [%v]", *argvConfig, err.Error())
+			os.Exit(2)
+		}
+		commanders = cfg.Commanders()
+	} else {
+		if *argvUrl == "" && *argvSql == "" {
+			fmt.Println("need --url or --sql parameter")
+			flag.Usage()
+			os.Exit(2)
+		}
+		cmd, err := makeCommander()
+		if err != nil {
+			log.Errorf("%v", err.Error())
+			os.Exit(2)
+		}
+		commanders = append(commanders, cmd)
+	}
+
+	var bDrift bool
+	for _, cmd := range commanders {
+		if cmd.Protobuf || len(cmd.Templates) > 0 {
+			log.Warnf("output [%v] of proto/template is not checked", cmd.OutDir)
+			continue
+		}
+		diffs, err := checkCommander(cmd)
+		if err != nil {
+			log.Errorf("check database [%v] output [%v] error [%v]", cmd.Database, cmd.OutDir, err.Error())
+			os.Exit(2)
+		}
+		if len(diffs) == 0 {
+			fmt.Printf("database [%v] output [%v] no difference\n", cmd.Database, cmd.OutDir)
+			continue
+		}
+		bDrift = true
+		fmt.Printf("--- %v\n+++ %v\n", cmd.Database, cmd.OutDir)
+		for _, v := range diffs {
+			fmt.Println(v.String())
+		}
+	}
+	if bDrift {
+		os.Exit(1)
+	}
+}
+
+// compare tables of DDL files or databases with generated files
+func checkCommander(cmd *schema.Commander) (diffs []*schema.SchemaDiff, err error) {
+
+	if len(cmd.SqlFiles) > 0 {
+		cmd = cmd.UseDatabase(cmd.Database)
+		var tables, schemas []*schema.TableSchema
+		if tables, err = ddl.ParseFiles(cmd.Database, cmd.SqlFiles...); err != nil {
+			return
+		}
+		for _, v := range tables {
+			if schema.IsTableIncluded(cmd, v.TableName) {
+				schemas = append(schemas, v)
+			}
+		}
+		return mysql.CheckGoStruct(cmd, schemas)
+	}
+
+	ui := sqlca.ParseUrl(cmd.ConnUrl)
+	if ui.Scheme != "mysql" {
+		return nil, fmt.Errorf("check of [%v] not implement yet", ui.Scheme)
+	}
+	e := sqlca.NewEngine(false)
+	e.SetLogger(sqlca.NewNopLogger())
+	e.Open(cmd.ConnUrl)
+
+	var databases []string
+	patterns := schema.TrimSpaceSlice(strings.Split(cmd.Database, ","))
+	if databases, err = mysql.QueryDatabases(e, patterns); err != nil {
+		return
+	}
+	for _, v := range databases {
+		var schemas []*schema.TableSchema
+		var ds []*schema.SchemaDiff
+		c := cmd.UseDatabase(v)
+		if len(databases) > 1 {
+			c.PackageName = v //same as exportMysqlDatabases
+		}
+		if schemas, err = mysql.QuerySchemas(c, e); err != nil {
+			return
+		}
+		if ds, err = mysql.CheckGoStruct(c, schemas); err != nil {
+			return
+		}
+		diffs = append(diffs, ds...)
+	}
+	return
+}
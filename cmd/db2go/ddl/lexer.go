@@ -0,0 +1,126 @@
+package ddl
+
+import (
+	"strings"
+)
+
+type tokenType int
+
+const (
+	tokenWord   tokenType = iota // keyword or identifier
+	tokenQuoted                  // quoted identifier `name` "name" [name]
+	tokenString                  // string literal 'value'
+	tokenNumber                  // number literal
+	tokenSymbol                  // ( ) , ; = . : and others
+)
+
+type token struct {
+	Type  tokenType
+	Value string
+}
+
+// identifier or keyword
+func (t token) isWord() bool {
+	return t.Type == tokenWord || t.Type == tokenQuoted
+}
+
+// keyword compare without case (quoted identifiers are never keywords)
+func (t token) is(strKeyword string) bool {
+	return t.Type == tokenWord && strings.EqualFold(t.Value, strKeyword)
+}
+
+func (t token) isSymbol(strSymbol string) bool {
+	return t.Type == tokenSymbol && t.Value == strSymbol
+}
+
+// split SQL into tokens, comments are dropped (include mysql /*!xxx */ executable comments)
+func tokenize(strSQL string) (tokens []token) {
+
+	s := strSQL
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '-' && i+1 < len(s) && s[i+1] == '-', c == '#':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(s) && s[i+1] == '*':
+			if end := strings.Index(s[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(s)
+			}
+		case c == '\'':
+			var sb strings.Builder
+			i++
+			for i < len(s) {
+				if s[i] == '\\' && i+1 < len(s) {
+					sb.WriteByte(unescape(s[i+1]))
+					i += 2
+					continue
+				}
+				if s[i] == '\'' {
+					if i+1 < len(s) && s[i+1] == '\'' {
+						sb.WriteByte('\'')
+						i += 2
+						continue
+					}
+					break
+				}
+				sb.WriteByte(s[i])
+				i++
+			}
+			i++
+			tokens = append(tokens, token{Type: tokenString, Value: sb.String()})
+		case c == '`' || c == '"' || c == '[':
+			end := c
+			if c == '[' {
+				end = ']'
+			}
+			j := strings.IndexByte(s[i+1:], end)
+			if j < 0 {
+				j = len(s) - i - 1
+			}
+			tokens = append(tokens, token{Type: tokenQuoted, Value: s[i+1 : i+1+j]})
+			i += j + 2
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(s) && (s[j] >= '0' && s[j] <= '9' || s[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{Type: tokenNumber, Value: s[i:j]})
+			i = j
+		case isWordChar(c):
+			j := i
+			for j < len(s) && (isWordChar(s[j]) || s[j] >= '0' && s[j] <= '9' || s[j] == '$') {
+				j++
+			}
+			tokens = append(tokens, token{Type: tokenWord, Value: s[i:j]})
+			i = j
+		default:
+			tokens = append(tokens, token{Type: tokenSymbol, Value: string(c)})
+			i++
+		}
+	}
+	return
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func unescape(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case '0':
+		return 0
+	}
+	return c
+}
@@ -0,0 +1,812 @@
+package ddl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+const (
+	INDEX_NAME_PRIMARY  = "PRIMARY"
+	EXTRA_AUTO_INCR     = "auto_increment"
+	EXTRA_ON_UPDATE     = "on update CURRENT_TIMESTAMP"
+	COLUMN_KEY_PRIMARY  = "PRI"
+	COLUMN_KEY_UNIQUE   = "UNI"
+	COLUMN_KEY_MULTIPLE = "MUL"
+	FK_RULE_NO_ACTION   = "NO ACTION"
+)
+
+// data types of postgres/sqlite/mssql to mysql DATA_TYPE, auto increment types are marked by serial
+var dataTypes = map[string]string{
+	"integer":                     "int",
+	"int4":                        "int",
+	"int2":                        "smallint",
+	"int8":                        "bigint",
+	"serial":                      "int",
+	"serial4":                     "int",
+	"smallserial":                 "smallint",
+	"serial2":                     "smallint",
+	"bigserial":                   "bigint",
+	"serial8":                     "bigint",
+	"character varying":           "varchar",
+	"nvarchar":                    "varchar",
+	"varchar2":                    "varchar",
+	"character":                   "char",
+	"nchar":                       "char",
+	"bpchar":                      "char",
+	"clob":                        "text",
+	"ntext":                       "text",
+	"citext":                      "text",
+	"double precision":            "double",
+	"float8":                      "double",
+	"float4":                      "float",
+	"boolean":                     "bool",
+	"timestamp with time zone":    "timestamp",
+	"timestamp without time zone": "timestamp",
+	"timestamptz":                 "timestamp",
+	"datetime2":                   "datetime",
+	"smalldatetime":               "datetime",
+	"time with time zone":         "time",
+	"time without time zone":      "time",
+	"timetz":                      "time",
+	"bytea":                       "blob",
+	"image":                       "blob",
+	"uuid":                        "char",
+	"uniqueidentifier":            "char",
+	"jsonb":                       "json",
+	"money":                       "decimal",
+	"numeric":                     "decimal",
+	"real":                        "double",
+	"inet":                        "varchar",
+	"cidr":                        "varchar",
+	"macaddr":                     "varchar",
+	"interval":                    "varchar",
+	"xml":                         "text",
+}
+
+var serialTypes = []string{"serial", "serial2", "serial4", "serial8", "smallserial", "bigserial"}
+
+// NUMERIC_PRECISION of integer and float types like mysql, [signed, unsigned]
+var numericPrecisions = map[string][2]int64{
+	"tinyint":   {3, 3},
+	"smallint":  {5, 5},
+	"mediumint": {7, 8},
+	"int":       {10, 10},
+	"bigint":    {19, 20},
+	"float":     {12, 12},
+	"double":    {22, 22},
+}
+
+// words of multiple word types, eg. character varying, double precision, timestamp with time zone
+var typeWords = []string{"varying", "precision", "with", "without", "time", "zone"}
+
+type parser struct {
+	tokens   []token
+	pos      int
+	tables   []*schema.TableSchema
+	dict     map[string]*schema.TableSchema
+	database string
+	enums    map[string]string //postgres enum types, eg. mood -> enum('sad','ok')
+}
+
+// parse CREATE TABLE/CREATE INDEX/COMMENT ON/ALTER TABLE ADD FOREIGN KEY statements of files (mysql/postgres/sqlite syntax)
+func ParseFiles(strDatabase string, strFiles ...string) (tables []*schema.TableSchema, err error) {
+
+	var sb strings.Builder
+	for _, v := range strFiles {
+		var data []byte
+		if data, err = ioutil.ReadFile(v); err != nil {
+			return
+		}
+		sb.Write(data)
+		sb.WriteString("\n;\n")
+	}
+	return Parse(strDatabase, sb.String())
+}
+
+// parse CREATE TABLE/CREATE INDEX/COMMENT ON/ALTER TABLE ADD FOREIGN KEY statements (mysql/postgres/sqlite syntax)
+func Parse(strDatabase, strSQL string) (tables []*schema.TableSchema, err error) {
+
+	p := &parser{
+		tokens:   tokenize(strSQL),
+		dict:     make(map[string]*schema.TableSchema),
+		database: strDatabase,
+		enums:    make(map[string]string),
+	}
+	for p.pos < len(p.tokens) {
+		start := p.pos
+		if err = p.parseStatement(); err != nil {
+			return
+		}
+		p.skipStatement(start)
+	}
+	for _, v := range p.tables {
+		makeColumnKeys(v)
+		p.resolveForeignKeys(v)
+	}
+	return p.tables, nil
+}
+
+func (p *parser) peek(n int) token {
+	if p.pos+n < len(p.tokens) {
+		return p.tokens[p.pos+n]
+	}
+	return token{Type: tokenSymbol, Value: ";"}
+}
+
+func (p *parser) next() token {
+	t := p.peek(0)
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return t
+}
+
+// consume keywords if all matched
+func (p *parser) accept(keywords ...string) bool {
+	for i, v := range keywords {
+		if !p.peek(i).is(v) {
+			return false
+		}
+	}
+	p.pos += len(keywords)
+	return true
+}
+
+func (p *parser) acceptSymbol(strSymbol string) bool {
+	if p.peek(0).isSymbol(strSymbol) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) isEnd() bool {
+	return p.pos >= len(p.tokens) || p.peek(0).isSymbol(";")
+}
+
+// skip to next statement (the position never goes back)
+func (p *parser) skipStatement(start int) {
+	if p.pos == start && !p.peek(0).isSymbol(";") { //empty statement, eg. /*!40101 SET NAMES utf8 */;
+		p.pos++
+	}
+	for !p.isEnd() {
+		p.next()
+	}
+	p.acceptSymbol(";")
+}
+
+// skip a parenthesized group if current token is '('
+func (p *parser) skipGroup() {
+	if !p.acceptSymbol("(") {
+		return
+	}
+	for depth := 1; depth > 0 && p.pos < len(p.tokens); {
+		t := p.next()
+		if t.isSymbol("(") {
+			depth++
+		} else if t.isSymbol(")") {
+			depth--
+		}
+	}
+}
+
+// table name (last part of schema.table)
+func (p *parser) parseName() string {
+	strName := p.next().Value
+	for p.peek(0).isSymbol(".") && p.peek(1).isWord() {
+		p.pos++
+		strName = p.next().Value
+	}
+	return strName
+}
+
+func (p *parser) parseStatement() (err error) {
+
+	switch {
+	case p.accept("CREATE"):
+		p.accept("OR", "REPLACE")
+		for p.accept("TEMPORARY") || p.accept("TEMP") || p.accept("UNLOGGED") || p.accept("GLOBAL") || p.accept("LOCAL") {
+		}
+		if p.accept("TABLE") {
+			return p.parseCreateTable()
+		}
+		if p.accept("TYPE") {
+			p.parseCreateType()
+			return
+		}
+		bUnique := p.accept("UNIQUE")
+		p.accept("CLUSTERED")
+		p.accept("NONCLUSTERED")
+		if p.accept("INDEX") {
+			p.parseCreateIndex(bUnique)
+		}
+	case p.accept("COMMENT", "ON"):
+		p.parseCommentOn()
+	case p.accept("ALTER", "TABLE"):
+		p.parseAlterTable()
+	}
+	return
+}
+
+func (p *parser) parseCreateTable() (err error) {
+
+	p.accept("IF", "NOT", "EXISTS")
+	table := &schema.TableSchema{SchemeName: p.database, TableName: p.parseName()}
+	if !p.acceptSymbol("(") {
+		return nil //CREATE TABLE ... AS SELECT or LIKE
+	}
+	if _, ok := p.dict[table.TableName]; ok {
+		return fmt.Errorf("table [%v] duplicated", table.TableName)
+	}
+	for !p.isEnd() {
+		if p.acceptSymbol(")") {
+			break
+		}
+		if p.acceptSymbol(",") {
+			continue
+		}
+		if err = p.parseTableItem(table); err != nil {
+			return
+		}
+	}
+	p.parseTableOptions(table)
+	p.tables = append(p.tables, table)
+	p.dict[table.TableName] = table
+	return
+}
+
+// column definition or table constraint
+func (p *parser) parseTableItem(table *schema.TableSchema) (err error) {
+
+	var strConstraint string
+	if p.accept("CONSTRAINT") {
+		if !p.peek(0).is("PRIMARY") && !p.peek(0).is("UNIQUE") && !p.peek(0).is("FOREIGN") && !p.peek(0).is("CHECK") {
+			strConstraint = p.next().Value
+		}
+	}
+	switch {
+	case p.accept("PRIMARY", "KEY"):
+		p.skipIndexOptions()
+		addIndex(table, INDEX_NAME_PRIMARY, true, p.parseIndexColumns())
+	case p.peek(0).is("UNIQUE"):
+		p.next()
+		_ = p.accept("KEY") || p.accept("INDEX")
+		strName := p.parseIndexName()
+		if strName == "" {
+			strName = strConstraint
+		}
+		p.skipIndexOptions()
+		addIndex(table, strName, true, p.parseIndexColumns())
+	case p.peek(0).is("KEY") || p.peek(0).is("INDEX") || p.peek(0).is("FULLTEXT") || p.peek(0).is("SPATIAL"):
+		p.next()
+		_ = p.accept("KEY") || p.accept("INDEX")
+		strName := p.parseIndexName()
+		p.skipIndexOptions()
+		addIndex(table, strName, false, p.parseIndexColumns())
+	case p.accept("FOREIGN", "KEY"):
+		p.parseForeignKey(table, strConstraint)
+	case p.peek(0).is("CHECK") || p.peek(0).is("EXCLUDE"):
+		p.skipItem()
+	default:
+		if !p.peek(0).isWord() {
+			return fmt.Errorf("table [%v] unexpected token [%v]", table.TableName, p.peek(0).Value)
+		}
+		p.parseColumn(table)
+	}
+	p.skipItem()
+	return
+}
+
+// FOREIGN KEY [name] (columns) REFERENCES table [(columns)] [ON DELETE/UPDATE action]
+func (p *parser) parseForeignKey(table *schema.TableSchema, strConstraint string) {
+
+	fk := schema.ForeignKey{Name: strConstraint}
+	if strName := p.parseIndexName(); fk.Name == "" {
+		fk.Name = strName
+	}
+	fk.Columns = p.parseIndexColumns()
+	if !p.accept("REFERENCES") {
+		return
+	}
+	fk.RefTable = p.parseName()
+	fk.RefColumns = p.parseIndexColumns()
+	p.parseReferenceOptions(&fk)
+	addForeignKey(table, fk)
+}
+
+// MATCH FULL, ON DELETE CASCADE, ON UPDATE SET NULL, DEFERRABLE...
+func (p *parser) parseReferenceOptions(fk *schema.ForeignKey) {
+
+	for !p.isEnd() && !p.peek(0).isSymbol(",") && !p.peek(0).isSymbol(")") {
+		switch {
+		case p.accept("ON", "DELETE"):
+			fk.OnDelete = p.parseReferenceAction()
+		case p.accept("ON", "UPDATE"):
+			fk.OnUpdate = p.parseReferenceAction()
+		case p.accept("MATCH"), p.accept("INITIALLY"):
+			p.next()
+		case p.accept("NOT", "DEFERRABLE"), p.accept("DEFERRABLE"):
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) parseReferenceAction() string {
+	switch {
+	case p.accept("SET", "NULL"):
+		return "SET NULL"
+	case p.accept("SET", "DEFAULT"):
+		return "SET DEFAULT"
+	case p.accept("NO", "ACTION"):
+		return "NO ACTION"
+	}
+	return strings.ToUpper(p.next().Value)
+}
+
+// ALTER TABLE [ONLY] table ADD [CONSTRAINT name] FOREIGN KEY ..., other alters are ignored
+func (p *parser) parseAlterTable() {
+
+	p.accept("IF", "EXISTS")
+	p.accept("ONLY")
+	table, ok := p.dict[p.parseName()]
+	if !ok {
+		return
+	}
+	for !p.isEnd() {
+		if p.accept("ADD") {
+			var strConstraint string
+			if p.accept("CONSTRAINT") && !p.peek(0).is("FOREIGN") {
+				strConstraint = p.next().Value
+			}
+			if p.accept("FOREIGN", "KEY") {
+				p.parseForeignKey(table, strConstraint)
+			}
+		}
+		for !p.isEnd() && !p.acceptSymbol(",") {
+			if p.peek(0).isSymbol("(") {
+				p.skipGroup()
+				continue
+			}
+			p.next()
+		}
+	}
+}
+
+// referenced columns omitted are primary key of referenced table
+func (p *parser) resolveForeignKeys(table *schema.TableSchema) {
+
+	for i, v := range table.ForeignKeys {
+		if len(v.RefColumns) > 0 {
+			continue
+		}
+		if ref, ok := p.dict[v.RefTable]; ok {
+			for _, idx := range ref.Indexes {
+				if idx.Name == INDEX_NAME_PRIMARY {
+					table.ForeignKeys[i].RefColumns = idx.Columns
+				}
+			}
+		}
+	}
+}
+
+// skip to ',' or ')' of current table item
+func (p *parser) skipItem() {
+	for !p.isEnd() && !p.peek(0).isSymbol(",") && !p.peek(0).isSymbol(")") {
+		if p.peek(0).isSymbol("(") {
+			p.skipGroup()
+			continue
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseIndexName() string {
+	if p.peek(0).isWord() && !p.peek(0).is("USING") {
+		return p.next().Value
+	}
+	return ""
+}
+
+// USING BTREE/HASH before columns
+func (p *parser) skipIndexOptions() {
+	for p.accept("USING") {
+		p.next()
+	}
+}
+
+// index columns, prefix length/ASC/DESC/expressions are ignored
+func (p *parser) parseIndexColumns() (columns []string) {
+
+	if !p.acceptSymbol("(") {
+		return
+	}
+	for !p.isEnd() && !p.acceptSymbol(")") {
+		t := p.next()
+		if t.isWord() {
+			columns = append(columns, t.Value)
+		}
+		for !p.isEnd() && !p.peek(0).isSymbol(",") && !p.peek(0).isSymbol(")") {
+			if p.peek(0).isSymbol("(") {
+				p.skipGroup()
+				continue
+			}
+			p.next()
+		}
+		p.acceptSymbol(",")
+	}
+	return
+}
+
+func (p *parser) parseColumn(table *schema.TableSchema) {
+
+	var strConstraint string
+	col := schema.TableColumn{Name: p.next().Value, IsNullable: "YES"}
+	p.parseColumnType(&col)
+	for !p.isEnd() && !p.peek(0).isSymbol(",") && !p.peek(0).isSymbol(")") {
+		switch {
+		case p.accept("NOT", "NULL"):
+			col.IsNullable = "NO"
+		case p.accept("NULL"):
+			col.IsNullable = "YES"
+		case p.accept("DEFAULT"):
+			p.parseDefault(&col)
+		case p.accept("AUTO_INCREMENT"), p.accept("AUTOINCREMENT"):
+			col.Extra = addExtra(col.Extra, EXTRA_AUTO_INCR)
+		case p.accept("IDENTITY"):
+			col.Extra = addExtra(col.Extra, EXTRA_AUTO_INCR)
+			p.skipGroup()
+		case p.accept("GENERATED"):
+			_ = p.accept("ALWAYS") || p.accept("BY", "DEFAULT")
+			p.accept("AS")
+			if p.accept("IDENTITY") {
+				col.Extra = addExtra(col.Extra, EXTRA_AUTO_INCR)
+			} else {
+				p.skipGroup()
+				col.Extra = addExtra(col.Extra, "VIRTUAL GENERATED")
+				if p.accept("STORED") {
+					col.Extra = strings.Replace(col.Extra, "VIRTUAL", "STORED", 1)
+				}
+			}
+			p.skipGroup()
+		case p.peek(0).is("AS") && p.peek(1).isSymbol("("):
+			p.next()
+			p.skipGroup()
+			col.Extra = addExtra(col.Extra, "VIRTUAL GENERATED")
+			if p.accept("STORED") || p.accept("PERSISTED") {
+				col.Extra = strings.Replace(col.Extra, "VIRTUAL", "STORED", 1)
+			}
+		case p.accept("PRIMARY", "KEY"):
+			addIndex(table, INDEX_NAME_PRIMARY, true, []string{col.Name})
+			_ = p.accept("ASC") || p.accept("DESC")
+			if p.accept("AUTOINCREMENT") {
+				col.Extra = addExtra(col.Extra, EXTRA_AUTO_INCR)
+			}
+		case p.accept("UNIQUE"):
+			_ = p.accept("KEY") || p.accept("INDEX")
+			addIndex(table, col.Name, true, []string{col.Name})
+		case p.accept("COMMENT"):
+			col.Comment = schema.ReplaceCRLF(p.next().Value)
+		case p.accept("ON", "UPDATE"):
+			p.next()
+			p.skipGroup()
+			col.Extra = addExtra(col.Extra, EXTRA_ON_UPDATE)
+		case p.accept("CHARACTER", "SET"), p.accept("CHARSET"), p.accept("COLLATE"):
+			p.next()
+		case p.accept("CONSTRAINT"):
+			strConstraint = p.next().Value
+		case p.accept("REFERENCES"):
+			fk := schema.ForeignKey{Name: strConstraint, Columns: []string{col.Name}, RefTable: p.parseName()}
+			fk.RefColumns = p.parseIndexColumns()
+			p.parseReferenceOptions(&fk)
+			addForeignKey(table, fk)
+		default:
+			if p.next().isSymbol("(") {
+				p.pos--
+				p.skipGroup()
+			}
+		}
+	}
+	table.Columns = append(table.Columns, col)
+}
+
+// data type with length/precision and unsigned/zerofill, eg. int(10) unsigned, character varying(32), decimal(10,2)
+func (p *parser) parseColumnType(col *schema.TableColumn) {
+
+	if !p.peek(0).isWord() {
+		return
+	}
+	var words = []string{strings.ToLower(p.parseName())}
+	if strEnum, ok := p.enums[words[0]]; ok {
+		col.DataType, col.ColumnType = "enum", strEnum
+		return
+	}
+	for p.peek(0).Type == tokenWord && inSlice(strings.ToLower(p.peek(0).Value), typeWords) {
+		words = append(words, strings.ToLower(p.next().Value))
+	}
+	strType := strings.Join(words, " ")
+	var strArgs string
+	if p.peek(0).isSymbol("(") {
+		start := p.pos
+		p.skipGroup()
+		strArgs = joinTokens(p.tokens[start:p.pos])
+		// timestamp(6) with time zone
+		for p.peek(0).Type == tokenWord && inSlice(strings.ToLower(p.peek(0).Value), typeWords) {
+			strType += " " + strings.ToLower(p.next().Value)
+		}
+	}
+	var bArray bool
+	for p.peek(0).isSymbol("[") && p.peek(1).isSymbol("]") {
+		p.pos += 2
+		bArray = true
+	}
+	if inSlice(strType, serialTypes) {
+		col.Extra = addExtra(col.Extra, EXTRA_AUTO_INCR)
+	}
+	if v, ok := dataTypes[strType]; ok {
+		strType = v
+	}
+	if bArray {
+		strType, strArgs = "text", ""
+	}
+	col.DataType = strType
+	col.ColumnType = strType + strArgs
+	for {
+		if p.accept("UNSIGNED") {
+			col.ColumnType += " unsigned"
+		} else if p.accept("ZEROFILL") {
+			col.ColumnType += " zerofill"
+		} else if p.accept("SIGNED") {
+		} else {
+			break
+		}
+	}
+	if (strType == "varchar" || strType == "char") && strArgs != "" {
+		fmt.Sscanf(strings.Trim(strArgs, "()"), "%d", &col.MaxLength)
+	}
+	if strType == "decimal" && strArgs != "" {
+		fmt.Sscanf(strings.Trim(strArgs, "()"), "%d,%d", &col.Precision, &col.Scale)
+	}
+	if v, ok := numericPrecisions[strType]; ok {
+		col.Precision = v[0]
+		if strings.Contains(col.ColumnType, "unsigned") {
+			col.Precision = v[1]
+		}
+	}
+	if strType == "bit" {
+		col.Precision = 1
+		fmt.Sscanf(strings.Trim(strArgs, "()"), "%d", &col.Precision)
+	}
+}
+
+func (p *parser) parseDefault(col *schema.TableColumn) {
+
+	var strValue string
+	var bNull bool
+	t := p.next()
+	switch {
+	case t.isSymbol("("):
+		p.pos--
+		start := p.pos
+		p.skipGroup()
+		strValue = joinTokens(p.tokens[start:p.pos])
+	case t.isSymbol("-") || t.isSymbol("+"):
+		strValue = t.Value + p.next().Value
+	case t.is("NULL"):
+		bNull = true
+	case t.Type == tokenWord:
+		strValue = t.Value
+		if strings.EqualFold(t.Value, "nextval") {
+			col.Extra = addExtra(col.Extra, EXTRA_AUTO_INCR)
+			bNull = true
+		}
+		if p.peek(0).isSymbol("(") {
+			start := p.pos
+			p.skipGroup()
+			if !strings.EqualFold(t.Value, "CURRENT_TIMESTAMP") && !strings.EqualFold(t.Value, "now") {
+				strValue += joinTokens(p.tokens[start:p.pos])
+			}
+		}
+		if strings.EqualFold(strValue, "CURRENT_TIMESTAMP") || strings.EqualFold(strValue, "now") {
+			strValue = "CURRENT_TIMESTAMP"
+		}
+	default:
+		strValue = t.Value
+	}
+	// postgres cast, eg. ''::character varying
+	for p.peek(0).isSymbol(":") && p.peek(1).isSymbol(":") {
+		p.pos += 2
+		p.parseColumnType(&schema.TableColumn{})
+	}
+	if !bNull {
+		col.HasDefault = true
+		col.Default = strValue
+	}
+}
+
+// ENGINE=InnoDB COMMENT='xxx' ...
+func (p *parser) parseTableOptions(table *schema.TableSchema) {
+
+	for !p.isEnd() {
+		switch {
+		case p.accept("ENGINE"):
+			p.acceptSymbol("=")
+			table.TableEngine = p.next().Value
+		case p.accept("COMMENT"):
+			p.acceptSymbol("=")
+			table.TableComment = schema.ReplaceCRLF(p.next().Value)
+		default:
+			p.next()
+		}
+	}
+}
+
+// CREATE TYPE name AS ENUM ('a', 'b') of postgres
+func (p *parser) parseCreateType() {
+
+	strName := strings.ToLower(p.parseName())
+	if !p.accept("AS", "ENUM") || !p.peek(0).isSymbol("(") {
+		return
+	}
+	start := p.pos
+	p.skipGroup()
+	p.enums[strName] = "enum" + joinTokens(p.tokens[start:p.pos])
+}
+
+// CREATE [UNIQUE] INDEX [CONCURRENTLY] [IF NOT EXISTS] name ON [ONLY] table [USING method] (columns)
+func (p *parser) parseCreateIndex(bUnique bool) {
+
+	p.accept("CONCURRENTLY")
+	p.accept("IF", "NOT", "EXISTS")
+	var strName string
+	if !p.peek(0).is("ON") {
+		strName = p.parseName()
+	}
+	if !p.accept("ON") {
+		return
+	}
+	p.accept("ONLY")
+	table, ok := p.dict[p.parseName()]
+	if !ok {
+		return
+	}
+	p.skipIndexOptions()
+	addIndex(table, strName, bUnique, p.parseIndexColumns())
+}
+
+// COMMENT ON TABLE name IS 'xxx' or COMMENT ON COLUMN table.column IS 'xxx'
+func (p *parser) parseCommentOn() {
+
+	switch {
+	case p.accept("TABLE"):
+		strTable := p.parseName()
+		if p.accept("IS") {
+			if table, ok := p.dict[strTable]; ok {
+				table.TableComment = schema.ReplaceCRLF(p.next().Value)
+			}
+		}
+	case p.accept("COLUMN"):
+		var names []string
+		names = append(names, p.next().Value)
+		for p.acceptSymbol(".") {
+			names = append(names, p.next().Value)
+		}
+		if len(names) < 2 || !p.accept("IS") {
+			return
+		}
+		strComment := schema.ReplaceCRLF(p.next().Value)
+		if table, ok := p.dict[names[len(names)-2]]; ok {
+			for i, v := range table.Columns {
+				if v.Name == names[len(names)-1] {
+					table.Columns[i].Comment = strComment
+				}
+			}
+		}
+	}
+}
+
+// add index, unnamed index is named by first column like mysql
+func addIndex(table *schema.TableSchema, strName string, bUnique bool, columns []string) {
+
+	if len(columns) == 0 {
+		return
+	}
+	if strName == "" {
+		strName = columns[0]
+	}
+	for i, v := range table.Indexes {
+		if v.Name == strName {
+			table.Indexes[i] = schema.TableIndex{Name: strName, IsUnique: bUnique, Columns: columns}
+			return
+		}
+	}
+	table.Indexes = append(table.Indexes, schema.TableIndex{Name: strName, IsUnique: bUnique, Columns: columns})
+}
+
+// COLUMN_KEY like mysql: PRI for primary key, UNI for single column unique index, MUL for first column of others
+// foreign key without name is named like mysql, eg. classes_ibfk_1, rules default to NO ACTION
+func addForeignKey(table *schema.TableSchema, fk schema.ForeignKey) {
+	if len(fk.Columns) == 0 || fk.RefTable == "" {
+		return
+	}
+	if fk.Name == "" {
+		fk.Name = fmt.Sprintf("%v_ibfk_%v", table.TableName, len(table.ForeignKeys)+1)
+	}
+	if fk.OnDelete == "" {
+		fk.OnDelete = FK_RULE_NO_ACTION
+	}
+	if fk.OnUpdate == "" {
+		fk.OnUpdate = FK_RULE_NO_ACTION
+	}
+	table.ForeignKeys = append(table.ForeignKeys, fk)
+}
+
+func makeColumnKeys(table *schema.TableSchema) {
+
+	//ordered by INDEX_NAME like information_schema which compares case-insensitively
+	sort.Slice(table.Indexes, func(i, j int) bool {
+		return strings.ToLower(table.Indexes[i].Name) < strings.ToLower(table.Indexes[j].Name)
+	})
+	var keys = make(map[string]string)
+	for _, idx := range table.Indexes {
+		if idx.Name == INDEX_NAME_PRIMARY {
+			for _, v := range idx.Columns {
+				keys[v] = COLUMN_KEY_PRIMARY
+			}
+		}
+	}
+	for _, idx := range table.Indexes {
+		strColumn := idx.Columns[0]
+		if idx.Name == INDEX_NAME_PRIMARY || keys[strColumn] == COLUMN_KEY_PRIMARY {
+			continue
+		}
+		if idx.IsUnique && len(idx.Columns) == 1 {
+			keys[strColumn] = COLUMN_KEY_UNIQUE
+		} else if keys[strColumn] == "" {
+			keys[strColumn] = COLUMN_KEY_MULTIPLE
+		}
+	}
+	for i, v := range table.Columns {
+		table.Columns[i].Key = keys[v.Name]
+		if keys[v.Name] == COLUMN_KEY_PRIMARY {
+			table.Columns[i].IsNullable = "NO"
+		}
+	}
+}
+
+// tokens to text, string literals are quoted again
+func joinTokens(tokens []token) (strText string) {
+	for _, t := range tokens {
+		switch {
+		case t.Type == tokenString:
+			strText += "'" + strings.Replace(t.Value, "'", "''", -1) + "'"
+		case t.isSymbol(","):
+			strText += t.Value
+		case t.Type == tokenWord && strText != "" && !strings.HasSuffix(strText, "(") && !strings.HasSuffix(strText, ","):
+			strText += " " + t.Value
+		default:
+			strText += t.Value
+		}
+	}
+	return
+}
+
+func addExtra(strExtra, strValue string) string {
+	if strings.Contains(strExtra, strValue) {
+		return strExtra
+	}
+	return strings.TrimSpace(strExtra + " " + strValue)
+}
+
+func inSlice(strIn string, s []string) bool {
+	for _, v := range s {
+		if v == strIn {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/civet148/gotools/log"
+	"github.com/civet148/sqlca"
+	"github.com/civet148/sqlca/cmd/db2go/mysql"
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+var argvFrom = flag.String("from", "", "source database url of diff (eg. staging)")
+var argvTo = flag.String("to", "", "target database url of diff (eg. production)")
+var argvDDL = flag.Bool("ddl", false, "output DDL statements which make target schema same as source")
+
+// db2go diff --from <url> --to <url> [--table users,classes] [--ddl]
+// exit with code 1 if any difference found (2 on error)
+func runDiff() {
+
+	if *argvFrom == "" || *argvTo == "" {
+		fmt.Println("need --from and --to parameter")
+		flag.Usage()
+		os.Exit(2)
+	}
+	from, eFrom, err := queryDiffSchemas(*argvFrom)
+	if err != nil {
+		log.Errorf("query source schema error [%v]", err.Error())
+		os.Exit(2)
+	}
+	to, _, err := queryDiffSchemas(*argvTo)
+	if err != nil {
+		log.Errorf("query target schema error [%v]", err.Error())
+		os.Exit(2)
+	}
+	diffs := schema.DiffSchemas(from, to)
+	if len(diffs) == 0 {
+		fmt.Println("no difference")
+		return
+	}
+	fmt.Printf("--- %v\n+++ %v\n", schema.GetDatabaseName(sqlca.ParseUrl(*argvFrom).Path), schema.GetDatabaseName(sqlca.ParseUrl(*argvTo).Path))
+	for _, v := range diffs {
+		fmt.Println(v.String())
+	}
+	if *argvDDL {
+		ddl, err := mysql.MakeDiffDDL(eFrom, diffs)
+		if err != nil {
+			log.Errorf("make DDL error [%v]", err.Error())
+			os.Exit(2)
+		}
+		fmt.Println()
+		fmt.Println(strings.Join(ddl, "\n"))
+	}
+	os.Exit(1)
+}
+
+func queryDiffSchemas(strUrl string) (schemas []*schema.TableSchema, e *sqlca.Engine, err error) {
+
+	var cmd schema.Commander
+	ui := sqlca.ParseUrl(strUrl)
+	cmd.Scheme = ui.Scheme
+	cmd.Database = schema.GetDatabaseName(ui.Path)
+	if *argvTables != "" {
+		cmd.Tables = schema.TrimSpaceSlice(strings.Split(*argvTables, ","))
+	}
+	if cmd.Scheme != "mysql" {
+		return nil, nil, fmt.Errorf("diff of [%v] not implement yet", cmd.Scheme)
+	}
+	e = sqlca.NewEngine(false)
+	e.SetLogger(sqlca.NewNopLogger())
+	if e.Open(strUrl) == nil {
+		return nil, nil, fmt.Errorf("open database [%v/%v] failed", ui.Host, cmd.Database)
+	}
+	schemas, err = mysql.QuerySchemas(&cmd, e)
+	return
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/civet148/gotools/log"
+	"github.com/civet148/sqlca"
+	"github.com/civet148/sqlca/migrate"
+)
+
+const (
+	MIGRATE_CMD_UP     = "up"
+	MIGRATE_CMD_DOWN   = "down"
+	MIGRATE_CMD_STATUS = "status"
+	MIGRATE_CMD_CREATE = "create"
+	MIGRATE_CMD_FORCE  = "force"
+)
+
+var argvDir = flag.String("dir", migrate.DEFAULT_MIGRATIONS_DIR, "migration files directory")
+
+// db2go [--url ...] [--dir migrations] migrate up [n] | down [n] | status | create <name> | force <version>
+func runMigrate(args []string) {
+
+	if len(args) == 0 {
+		fmt.Println("usage: db2go --url <url> --dir <dir> migrate up [n] | down [n] | status | create <name> | force <version>")
+		return
+	}
+	strCmd := args[0]
+	args = args[1:]
+
+	if strCmd == MIGRATE_CMD_CREATE {
+		if len(args) == 0 {
+			fmt.Println("need migration name, eg. db2go migrate create create_users")
+			return
+		}
+		strUp, strDown, err := migrate.Create(*argvDir, args[0])
+		if err != nil {
+			log.Errorf("create migration error [%v]", err.Error())
+			os.Exit(1)
+		}
+		fmt.Printf("created %v\ncreated %v\n", strUp, strDown)
+		return
+	}
+
+	if *argvUrl == "" {
+		fmt.Println("need --url parameter")
+		flag.Usage()
+		return
+	}
+	e := sqlca.NewEngine(false)
+	e.SetLogger(sqlca.NewNopLogger())
+	e.Open(*argvUrl)
+
+	m := migrate.NewMigrator(e, &migrate.Options{Dir: *argvDir})
+	if err := m.Load(); err != nil && strCmd != MIGRATE_CMD_FORCE {
+		log.Errorf("load migrations from [%v] error [%v]", *argvDir, err.Error())
+		os.Exit(1)
+	}
+
+	var err error
+	switch strCmd {
+	case MIGRATE_CMD_UP:
+		err = m.Up(getMigrateSteps(args, 0))
+	case MIGRATE_CMD_DOWN:
+		err = m.Down(getMigrateSteps(args, 1))
+	case MIGRATE_CMD_STATUS:
+		err = printMigrateStatus(m)
+	case MIGRATE_CMD_FORCE:
+		if len(args) == 0 {
+			fmt.Println("need migration version, eg. db2go migrate force 20200316103000")
+			return
+		}
+		var version int64
+		if version, err = strconv.ParseInt(args[0], 10, 64); err == nil {
+			err = m.Force(version)
+		}
+	default:
+		fmt.Printf("unknown migrate command [%v]\n", strCmd)
+		return
+	}
+	if err == migrate.ErrNoChange {
+		fmt.Println("no change")
+		return
+	}
+	if err != nil {
+		log.Errorf("migrate %v error [%v]", strCmd, err.Error())
+		os.Exit(1)
+	}
+	if strCmd != MIGRATE_CMD_STATUS {
+		fmt.Printf("migrate %v ok\n", strCmd)
+	}
+}
+
+func getMigrateSteps(args []string, def int) int {
+	if len(args) == 0 {
+		return def
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		log.Errorf("steps [%v] is not a number", args[0])
+		os.Exit(1)
+	}
+	return n
+}
+
+func printMigrateStatus(m *migrate.Migrator) (err error) {
+
+	var status []*migrate.MigrationStatus
+	if status, err = m.Status(); err != nil {
+		return
+	}
+	fmt.Printf("%-16v %-40v %-10v %v\n", "VERSION", "NAME", "STATE", "APPLIED AT")
+	for _, v := range status {
+		strState := "pending"
+		switch {
+		case v.Dirty:
+			strState = "dirty"
+		case v.Missing:
+			strState = "missing"
+		case v.Modified:
+			strState = "modified"
+		case v.Applied:
+			strState = "applied"
+		}
+		fmt.Printf("%-16v %-40v %-10v %v\n", v.Version, v.Name, strState, v.AppliedAt)
+	}
+	return
+}
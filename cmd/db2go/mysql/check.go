@@ -0,0 +1,190 @@
+package mysql
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+// field of generated struct
+type structField struct {
+	Column string //column name of db tag
+	GoType string
+}
+
+// compare go structures generated from tables with existing files in output directory
+// missing: table/column exists in database but not in generated files, extra: exists in generated files but not in database
+func CheckGoStruct(cmd *schema.Commander, tables []*schema.TableSchema) (diffs []*schema.SchemaDiff, err error) {
+
+	strPackage := cmd.PackageName
+	if strPackage == "" {
+		strPackage = cmd.Database //same as exported without package name
+	}
+	strDir := filepath.Join(cmd.OutDir, strPackage)
+
+	var existTables map[string][]*structField
+	if existTables, err = parseGoStructs(strDir); err != nil {
+		return
+	}
+	var names = make(map[string]bool)
+	for _, v := range tables {
+		names[v.TableName] = true
+		fields, ok := existTables[v.TableName]
+		if !ok {
+			diffs = append(diffs, &schema.SchemaDiff{Kind: schema.DIFF_KIND_TABLE, Action: schema.DIFF_ACTION_MISSING, Table: v.TableName, FromTable: v})
+			continue
+		}
+		var expects []*structField
+		if expects, err = makeStructFields(cmd, v); err != nil {
+			return
+		}
+		diffs = append(diffs, diffStructFields(v, expects, fields)...)
+	}
+	var extras []string
+	for k := range existTables {
+		if !names[k] && schema.IsTableIncluded(cmd, k) {
+			extras = append(extras, k)
+		}
+	}
+	sort.Strings(extras)
+	for _, v := range extras {
+		diffs = append(diffs, &schema.SchemaDiff{Kind: schema.DIFF_KIND_TABLE, Action: schema.DIFF_ACTION_EXTRA, Table: v})
+	}
+	return
+}
+
+// regenerate structure of table in memory and parse fields of it
+func makeStructFields(cmd *schema.Commander, table *schema.TableSchema) (fields []*structField, err error) {
+
+	table.StructName = fmt.Sprintf("%vDO", schema.CamelCaseConvert(table.TableName))
+	strSource := "package check\n\n" + makeTableStructure(cmd, table)
+	var file *ast.File
+	if file, err = parser.ParseFile(token.NewFileSet(), table.TableName+".go", strSource, 0); err != nil {
+		return nil, fmt.Errorf("parse generated structure of table [%v] error [%v]", table.TableName, err.Error())
+	}
+	structs, _ := collectStructs(file)
+	return structs[table.StructName], nil
+}
+
+func diffStructFields(table *schema.TableSchema, expects, fields []*structField) (diffs []*schema.SchemaDiff) {
+
+	var exists = make(map[string]*structField)
+	for _, v := range fields {
+		exists[v.Column] = v
+	}
+	var columns = make(map[string]bool)
+	for _, v := range expects {
+		columns[v.Column] = true
+		f, ok := exists[v.Column]
+		if !ok {
+			diffs = append(diffs, &schema.SchemaDiff{Kind: schema.DIFF_KIND_COLUMN, Action: schema.DIFF_ACTION_MISSING, Table: table.TableName, Name: v.Column,
+				Details: []string{fmt.Sprintf("type [%v]", v.GoType)}, FromTable: table})
+			continue
+		}
+		if f.GoType != v.GoType {
+			diffs = append(diffs, &schema.SchemaDiff{Kind: schema.DIFF_KIND_COLUMN, Action: schema.DIFF_ACTION_CHANGED, Table: table.TableName, Name: v.Column,
+				Details: []string{fmt.Sprintf("type [%v] -> [%v]", v.GoType, f.GoType)}, FromTable: table})
+		}
+	}
+	for _, v := range fields {
+		if !columns[v.Column] {
+			diffs = append(diffs, &schema.SchemaDiff{Kind: schema.DIFF_KIND_COLUMN, Action: schema.DIFF_ACTION_EXTRA, Table: table.TableName, Name: v.Column,
+				Details: []string{fmt.Sprintf("type [%v]", v.GoType)}, FromTable: table})
+		}
+	}
+	return
+}
+
+// parse go files in directory, returns struct fields by table name (var TableNameXxx = "table" and struct XxxDO)
+func parseGoStructs(strDir string) (tables map[string][]*structField, err error) {
+
+	tables = make(map[string][]*structField)
+	var files []string
+	if files, err = filepath.Glob(filepath.Join(strDir, "*.go")); err != nil {
+		return
+	}
+	if len(files) == 0 {
+		if _, errStat := os.Stat(strDir); errStat != nil {
+			return nil, fmt.Errorf("output directory [%v] not found", strDir)
+		}
+	}
+	var structs = make(map[string][]*structField)
+	var names = make(map[string]string) //table name -> struct name
+	fset := token.NewFileSet()
+	for _, v := range files {
+		if strings.HasSuffix(v, "_test.go") {
+			continue
+		}
+		var file *ast.File
+		if file, err = parser.ParseFile(fset, v, nil, 0); err != nil {
+			return
+		}
+		s, n := collectStructs(file)
+		for k, f := range s {
+			structs[k] = f
+		}
+		for k, t := range n {
+			names[k] = t
+		}
+	}
+	for k, v := range names {
+		tables[k] = structs[v]
+	}
+	return
+}
+
+// collect fields with db tag of structs and table names declared by var TableNameXxx = "table"
+func collectStructs(file *ast.File) (structs map[string][]*structField, names map[string]string) {
+
+	structs = make(map[string][]*structField)
+	names = make(map[string]string)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			switch s := spec.(type) {
+			case *ast.ValueSpec:
+				for i, id := range s.Names {
+					if !strings.HasPrefix(id.Name, "TableName") || i >= len(s.Values) {
+						continue
+					}
+					if lit, ok := s.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+						strTable, _ := strconv.Unquote(lit.Value)
+						names[strTable] = strings.TrimPrefix(id.Name, "TableName") + "DO"
+					}
+				}
+			case *ast.TypeSpec:
+				st, ok := s.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				var fields []*structField
+				for _, f := range st.Fields.List {
+					if f.Tag == nil {
+						continue
+					}
+					strTag, _ := strconv.Unquote(f.Tag.Value)
+					strColumn := reflect.StructTag(strTag).Get("db")
+					if strColumn == "" || strColumn == "-" {
+						continue
+					}
+					fields = append(fields, &structField{Column: strColumn, GoType: types.ExprString(f.Type)})
+				}
+				structs[s.Name.Name] = fields
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,75 @@
+package mysql
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/civet148/sqlca/cmd/db2go/ddl"
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+func TestCheckGoStruct(t *testing.T) {
+
+	tables, err := ddl.ParseFiles("test", testTypesSql)
+	if err != nil {
+		t.Fatalf("parse error (%v)", err)
+	}
+	opt := schema.Commander{NullAsPointer: true, Tables: []string{"accounts", "orders", "old_*"}}
+	strOutDir := exportFixture(t, opt, func(cmd *schema.Commander) error {
+		return ExportSchemas(cmd, tables)
+	})
+	defer os.RemoveAll(strOutDir)
+
+	cmd := opt
+	cmd.OutDir, cmd.PackageName = strOutDir, "models"
+	diffs, err := CheckGoStruct(&cmd, tables)
+	if err != nil || len(diffs) != 0 {
+		t.Fatalf("no difference of generated files expected, got %v error %v", diffs, err)
+	}
+	//the same tables without option changes nullable types
+	if diffs, _ = CheckGoStruct(&schema.Commander{OutDir: strOutDir, PackageName: "models"}, tables); len(diffs) == 0 {
+		t.Errorf("changed types of nullable columns expected")
+	}
+
+	//drift: orders.go removed, a column of accounts changed and added, extra tables of generated files
+	strPkgDir := filepath.Join(strOutDir, "models")
+	if err = os.Remove(filepath.Join(strPkgDir, "orders.go")); err != nil {
+		t.Fatalf("remove error (%v)", err)
+	}
+	strFile := filepath.Join(strPkgDir, "accounts.go")
+	data, err := ioutil.ReadFile(strFile)
+	if err != nil {
+		t.Fatalf("read error (%v)", err)
+	}
+	strCode := strings.Replace(string(data), "Uuid string `", "Uuid int64 `", 1)
+	strCode = strings.Replace(strCode, "type AccountsDO struct { ", "type AccountsDO struct { \n\tOld string `db:\"old\"`", 1)
+	_ = ioutil.WriteFile(strFile, []byte(strCode), 0644)
+	strExtra := "package models\n\nvar TableNameOldLogs = \"old_logs\"\nvar TableNameSkipped = \"skipped\"\n\n" +
+		"type OldLogsDO struct {\n\tId int64 `db:\"id\"`\n}\n\ntype SkippedDO struct {\n\tId int64 `db:\"id\"`\n}\n"
+	_ = ioutil.WriteFile(filepath.Join(strPkgDir, "old_logs.go"), []byte(strExtra), 0644)
+	_ = ioutil.WriteFile(filepath.Join(strPkgDir, "old_test.go"), []byte("package models\n\nvar TableNameOldTests = \"old_tests\"\n"), 0644)
+
+	if diffs, err = CheckGoStruct(&cmd, tables); err != nil {
+		t.Fatalf("check error (%v)", err)
+	}
+	var lines []string
+	for _, v := range diffs {
+		lines = append(lines, v.String())
+	}
+	expect := []string{
+		"[changed] column accounts.uuid type [string] -> [int64]",
+		"[extra  ] column accounts.old type [string]",
+		"[missing] table  orders",
+		"[extra  ] table  old_logs",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expect, "\n") {
+		t.Errorf("\n%v\nexpected, got\n%v", strings.Join(expect, "\n"), strings.Join(lines, "\n"))
+	}
+
+	if _, err = CheckGoStruct(&schema.Commander{OutDir: strOutDir, PackageName: "no_such_package"}, tables); err == nil {
+		t.Errorf("output directory not found error expected")
+	}
+}
@@ -0,0 +1,234 @@
+package mysql
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/civet148/gotools/log"
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+const (
+	PROTO_DECIMAL_DOUBLE = "double"
+	PROTO_DECIMAL_STRING = "string"
+	PROTO_DECIMAL_INT64  = "int64" //decimal(N,0) only, others are double
+)
+
+const (
+	IMPORT_STRCONV = "strconv"
+	PB_ALIAS       = "pb"
+)
+
+// golang type of protobuf scalar type generated by protoc-gen-go
+var protoGoTypes = map[string]string{
+	"double": "float64",
+	"float":  "float32",
+	"int32":  "int32",
+	"int64":  "int64",
+	"uint32": "uint32",
+	"uint64": "uint64",
+	"bool":   "bool",
+	"string": "string",
+}
+
+// export ToProto()/FromProto() of DO to file <table file>_proto.go, must be called after table structure made
+func exportTableConverter(cmd *schema.Commander, table *schema.TableSchema) (err error) {
+
+	strFileName := strings.TrimSuffix(table.FileName, ".go") + "_proto.go"
+	var File *os.File
+	File, err = os.OpenFile(strFileName, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0)
+	if err != nil {
+		log.Errorf("open file [%v] error (%v)", strFileName, err.Error())
+		return
+	}
+	defer File.Close()
+	log.Infof("exporting table proto converter [%v] to file [%v]", table.TableName, strFileName)
+
+	strBody, imports := makeConverter(cmd, table)
+	var strContent string
+	strContent += fmt.Sprintf("// Code generated by db2go. DO NOT EDIT.\n")
+	strContent += fmt.Sprintf("package %v\n\n", cmd.PackageName)
+	strContent += "import (\n"
+	for _, v := range imports {
+		strContent += fmt.Sprintf("\t%q\n", v)
+	}
+	strContent += fmt.Sprintf("\t%v %q\n)\n\n", PB_ALIAS, cmd.PbPackage)
+	strContent += strBody
+	_, err = File.WriteString(strContent)
+	return
+}
+
+// converters between DO and proto message, columns of type override are not converted
+func makeConverter(cmd *schema.Commander, table *schema.TableSchema) (strContent string, imports []string) {
+
+	strDo := table.StructName
+	strPb := fmt.Sprintf("%v.%v", PB_ALIAS, strDo)
+	var strTo, strFrom string
+	for i, v := range table.Columns {
+
+		col := &table.Columns[i]
+		if schema.IsColumnIn(cmd.Without, table.TableName, v.Name) {
+			continue
+		}
+		strGoName := schema.CamelCaseConvert(v.Name)
+		strPbName := getProtoGoName(v.Name)
+		if _, ok := getTypeOverride(cmd, table.TableName, v.Name); ok {
+			strTo += fmt.Sprintf("\t//column %v of type override is not converted\n", v.Name)
+			strFrom += fmt.Sprintf("\t//column %v of type override is not converted\n", v.Name)
+			continue
+		}
+		strGoType, _ := getColumnGoType(cmd, table, col)
+		strProtoType := getColumnProtoType(cmd, table, col)
+		bPointer := strings.HasPrefix(strGoType, "*")
+		strGoType = strings.TrimPrefix(strGoType, "*")
+		if isEnumColumn(col) {
+			strProtoType = "enum"
+		}
+
+		strField := "do." + strGoName
+		if bPointer {
+			strTo += fmt.Sprintf("\tif %v != nil {\n\t\tm.%v = %v\n\t}\n", strField, strPbName, makeToProto(strGoType, strProtoType, strField, "*"+strField))
+			strFrom += fmt.Sprintf("\t%v = new(%v)\n", strField, strGoType)
+			strFrom += fmt.Sprintf("\t%v\n", makeFromProto(strGoType, strProtoType, "*"+strField, strField, "m."+strPbName))
+		} else {
+			strTo += fmt.Sprintf("\tm.%v = %v\n", strPbName, makeToProto(strGoType, strProtoType, strField, strField))
+			strFrom += fmt.Sprintf("\t%v\n", makeFromProto(strGoType, strProtoType, strField, strField, "m."+strPbName))
+		}
+	}
+	strContent += makeEnumConverters(cmd, table)
+	strContent += fmt.Sprintf("// convert to proto message %v\n", strPb)
+	strContent += fmt.Sprintf("func (do *%v) ToProto() (m *%v) {\n", strDo, strPb)
+	strContent += fmt.Sprintf("\tif do == nil {\n\t\treturn nil\n\t}\n\tm = &%v{}\n", strPb)
+	strContent += strTo
+	strContent += "\treturn\n}\n\n"
+	strContent += fmt.Sprintf("// convert from proto message %v, nullable column of pointer type is always set\n", strPb)
+	strContent += fmt.Sprintf("func (do *%v) FromProto(m *%v) *%v {\n", strDo, strPb, strDo)
+	strContent += "\tif m == nil {\n\t\treturn do\n\t}\n"
+	strContent += strFrom
+	strContent += "\treturn do\n}\n\n"
+	for _, v := range []string{IMPORT_STRCONV, IMPORT_STRINGS, IMPORT_SQLCA} {
+		if strings.Contains(strContent, v[strings.LastIndex(v, "/")+1:]+".") {
+			imports = append(imports, v)
+		}
+	}
+	return
+}
+
+// expression converts golang value to proto value, x is used as method receiver and xv as value
+func makeToProto(strGoType, strProtoType, x, xv string) string {
+
+	switch {
+	case strProtoType == "enum":
+		return fmt.Sprintf("%v.ToProto()", x)
+	case strGoType == "sqlca.Decimal" && strProtoType == "string":
+		return fmt.Sprintf("%v.String()", x)
+	case strGoType == "sqlca.Decimal" && strProtoType == "int64":
+		return fmt.Sprintf("%v.IntPart()", x)
+	case strGoType == "sqlca.Decimal":
+		return fmt.Sprintf("%v.Float64()", x)
+	case strProtoType == "string" && strGoType == "float64":
+		return fmt.Sprintf("strconv.FormatFloat(%v, 'f', -1, 64)", xv)
+	case strProtoType == "string" && strGoType == "int64":
+		return fmt.Sprintf("strconv.FormatInt(%v, 10)", xv)
+	case protoGoTypes[strProtoType] == strGoType:
+		return xv
+	}
+	return fmt.Sprintf("%v(%v)", protoGoTypes[strProtoType], xv)
+}
+
+// statement converts proto value to golang value, dst is assigned and dstRecv is used as method receiver
+func makeFromProto(strGoType, strProtoType, dst, dstRecv, src string) string {
+
+	switch {
+	case strProtoType == "enum":
+		return fmt.Sprintf("%v.FromProto(%v)", dstRecv, src)
+	case strGoType == "sqlca.Decimal" && strProtoType == "string":
+		return fmt.Sprintf("%v.FromString(%v)", dstRecv, src)
+	case strGoType == "sqlca.Decimal" && strProtoType == "int64":
+		return fmt.Sprintf("%v.FromInt(%v)", dstRecv, src)
+	case strGoType == "sqlca.Decimal":
+		return fmt.Sprintf("%v.FromFloat(%v)", dstRecv, src)
+	case strProtoType == "string" && strGoType == "float64":
+		return fmt.Sprintf("%v, _ = strconv.ParseFloat(%v, 64)", dst, src)
+	case strProtoType == "string" && strGoType == "int64":
+		return fmt.Sprintf("%v, _ = strconv.ParseInt(%v, 10, 64)", dst, src)
+	case protoGoTypes[strProtoType] == strGoType:
+		return fmt.Sprintf("%v = %v", dst, src)
+	}
+	return fmt.Sprintf("%v = %v(%v)", dst, strGoType, src)
+}
+
+// ToProto()/FromProto() of enum/set types, set is converted to repeated proto enum
+func makeEnumConverters(cmd *schema.Commander, table *schema.TableSchema) (strContent string) {
+
+	for i, v := range table.Columns {
+
+		col := &table.Columns[i]
+		if schema.IsColumnIn(cmd.Without, table.TableName, v.Name) || !isEnumColumn(col) {
+			continue
+		}
+		if _, ok := getTypeOverride(cmd, table.TableName, v.Name); ok {
+			continue
+		}
+		strType := getEnumTypeName(table, col)
+		strPbType := fmt.Sprintf("%v.%v", PB_ALIAS, strType)
+		idents := makeEnumIdents(parseEnumValues(v.ColumnType))
+
+		var strToCases, strFromCases string
+		for _, s := range idents {
+			strPbValue := fmt.Sprintf("%v_%v", strPbType, getProtoEnumValue(table, col, s))
+			if v.DataType == DATA_TYPE_SET {
+				strToCases += fmt.Sprintf("\t\tcase %v%v:\n\t\t\tvalues = append(values, %v)\n", strType, s, strPbValue)
+				strFromCases += fmt.Sprintf("\t\tcase %v:\n\t\t\tvalues = append(values, string(%v%v))\n", strPbValue, strType, s)
+			} else {
+				strToCases += fmt.Sprintf("\tcase %v%v:\n\t\treturn %v\n", strType, s, strPbValue)
+				strFromCases += fmt.Sprintf("\tcase %v:\n\t\t*v = %v%v\n", strPbValue, strType, s)
+			}
+		}
+		if v.DataType == DATA_TYPE_SET {
+			strContent += "// convert to repeated proto enum, unknown members are dropped\n"
+			strContent += fmt.Sprintf("func (v %v) ToProto() (values []%v) {\n", strType, strPbType)
+			strContent += "\tif v == \"\" {\n\t\treturn nil\n\t}\n"
+			strContent += fmt.Sprintf("\tfor _, s := range strings.Split(string(v), \",\") {\n\t\tswitch %v(s) {\n%v\t\t}\n\t}\n\treturn\n}\n\n", strType, strToCases)
+			strContent += "// convert from repeated proto enum, unspecified is dropped\n"
+			strContent += fmt.Sprintf("func (v *%v) FromProto(p []%v) {\n", strType, strPbType)
+			strContent += fmt.Sprintf("\tvar values []string\n\tfor _, e := range p {\n\t\tswitch e {\n%v\t\t}\n\t}\n", strFromCases)
+			strContent += fmt.Sprintf("\t*v = %v(strings.Join(values, \",\"))\n}\n\n", strType)
+		} else {
+			strContent += "// convert to proto enum, empty or unknown value is unspecified\n"
+			strContent += fmt.Sprintf("func (v %v) ToProto() %v {\n", strType, strPbType)
+			strContent += fmt.Sprintf("\tswitch v {\n%v\t}\n\treturn %v_%v\n}\n\n", strToCases, strPbType, getProtoEnumValue(table, col, PROTO_ENUM_UNSPECIFIED))
+			strContent += "// convert from proto enum, unspecified is empty value\n"
+			strContent += fmt.Sprintf("func (v *%v) FromProto(p %v) {\n", strType, strPbType)
+			strContent += fmt.Sprintf("\tswitch p {\n%v\tdefault:\n\t\t*v = \"\"\n\t}\n}\n\n", strFromCases)
+		}
+	}
+	return
+}
+
+// golang field name of proto field generated by protoc-gen-go, eg. user_id -> UserId, field_1 -> Field_1
+func getProtoGoName(strName string) string {
+
+	var sb strings.Builder
+	for i := 0; i < len(strName); i++ {
+		c := strName[i]
+		switch {
+		case c == '_' && i == 0:
+			sb.WriteByte('X')
+		case c == '_' && i+1 < len(strName) && strName[i+1] >= 'a' && strName[i+1] <= 'z':
+			//skip underscore before lower letter
+		case c >= '0' && c <= '9':
+			sb.WriteByte(c)
+		default:
+			if c >= 'a' && c <= 'z' {
+				c -= 'a' - 'A'
+			}
+			sb.WriteByte(c)
+			for ; i+1 < len(strName) && strName[i+1] >= 'a' && strName[i+1] <= 'z'; i++ {
+				sb.WriteByte(strName[i+1])
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,114 @@
+package mysql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+func TestGetProtoGoName(t *testing.T) {
+	for k, v := range map[string]string{"user_id": "UserId", "id": "Id", "field_1": "Field_1", "_x": "XX", "a__b": "A_B", "login_at": "LoginAt"} {
+		if s := getProtoGoName(k); s != v {
+			t.Errorf("proto go name of %v: %v expected, got %v", k, v, s)
+		}
+	}
+}
+
+func TestMakeProtoConversion(t *testing.T) {
+
+	var cases = []struct {
+		strGoType    string
+		strProtoType string
+		strTo        string
+		strFrom      string
+	}{
+		{"int64", "int64", "do.Amount", "do.Amount = m.Amount"},
+		{"uint8", "uint32", "uint32(do.Amount)", "do.Amount = uint8(m.Amount)"},
+		{"sqlca.Decimal", "double", "do.Amount.Float64()", "do.Amount.FromFloat(m.Amount)"},
+		{"sqlca.Decimal", "string", "do.Amount.String()", "do.Amount.FromString(m.Amount)"},
+		{"sqlca.Decimal", "int64", "do.Amount.IntPart()", "do.Amount.FromInt(m.Amount)"},
+		{"float64", "string", "strconv.FormatFloat(do.Amount, 'f', -1, 64)", "do.Amount, _ = strconv.ParseFloat(m.Amount, 64)"},
+		{"AccountsState", "enum", "do.Amount.ToProto()", "do.Amount.FromProto(m.Amount)"},
+	}
+	for _, v := range cases {
+		if s := makeToProto(v.strGoType, v.strProtoType, "do.Amount", "do.Amount"); s != v.strTo {
+			t.Errorf("%v to %v: %v expected, got %v", v.strGoType, v.strProtoType, v.strTo, s)
+		}
+		if s := makeFromProto(v.strGoType, v.strProtoType, "do.Amount", "do.Amount", "m.Amount"); s != v.strFrom {
+			t.Errorf("%v from %v: %v expected, got %v", v.strGoType, v.strProtoType, v.strFrom, s)
+		}
+	}
+}
+
+func TestMakeConverter(t *testing.T) {
+
+	table, _ := loadTable(t, "accounts", testTypesSql)
+	table.StructName = "AccountsDO"
+	cmd := &schema.Commander{PbPackage: "example.com/pb", ProtoDecimal: PROTO_DECIMAL_STRING, NullAsPointer: true,
+		Without: []string{"avatar"}, TypeOverrides: map[string]string{"accounts.profile": "encoding/json.RawMessage"}}
+	strContent, imports := makeConverter(cmd, table)
+	for _, v := range []string{
+		"func (do *AccountsDO) ToProto() (m *pb.AccountsDO) {\n\tif do == nil {\n\t\treturn nil\n\t}\n\tm = &pb.AccountsDO{}\n\tm.Id = do.Id\n",
+		"func (do *AccountsDO) FromProto(m *pb.AccountsDO) *AccountsDO {\n\tif m == nil {\n\t\treturn do\n\t}\n",
+		"\tm.Age = uint32(do.Age)\n",
+		"\tdo.Age = uint8(m.Age)\n",
+		//decimal as proto string, nullable decimal as pointer
+		"\tm.Amount = do.Amount.String()\n",
+		"\tif do.Rate != nil {\n\t\tm.Rate = do.Rate.String()\n\t}\n",
+		"\tdo.Rate = new(sqlca.Decimal)\n\tdo.Rate.FromString(m.Rate)\n",
+		"\tif do.Nick != nil {\n\t\tm.Nick = *do.Nick\n\t}\n",
+		"\tdo.Nick = new(string)\n\t*do.Nick = m.Nick\n",
+		//enum and set
+		"\tm.State = do.State.ToProto()\n",
+		"\tdo.Tags = new(AccountsTags)\n\tdo.Tags.FromProto(m.Tags)\n",
+		"func (v AccountsState) ToProto() pb.AccountsState {\n\tswitch v {\n\tcase AccountsStateNormal:\n\t\treturn pb.AccountsState_ACCOUNTS_STATE_NORMAL\n",
+		"\treturn pb.AccountsState_ACCOUNTS_STATE_UNSPECIFIED\n}\n",
+		"func (v *AccountsTags) FromProto(p []pb.AccountsTags) {\n",
+		"\t*v = AccountsTags(strings.Join(values, \",\"))\n",
+		"\t//column profile of type override is not converted\n",
+	} {
+		if !strings.Contains(strContent, v) {
+			t.Errorf("converter must contain [%v]\n%v", v, strContent)
+		}
+	}
+	if strings.Contains(strContent, "Avatar") || strings.Contains(strContent, "Profile") {
+		t.Errorf("excluded and overridden columns must not be converted\n%v", strContent)
+	}
+	if !reflect.DeepEqual(imports, []string{IMPORT_STRINGS, IMPORT_SQLCA}) {
+		t.Errorf("imports of strings and sqlca expected, got %v", imports)
+	}
+
+	//decimal(N,0) as int64, others as double
+	strContent, _ = makeConverter(&schema.Commander{ProtoDecimal: PROTO_DECIMAL_INT64}, table)
+	for _, v := range []string{"\tm.Amount = do.Amount.IntPart()\n", "\tm.Rate = do.Rate.Float64()\n", "\tdo.Rate.FromFloat(m.Rate)\n"} {
+		if !strings.Contains(strContent, v) {
+			t.Errorf("converter must contain [%v]\n%v", v, strContent)
+		}
+	}
+}
+
+func TestMakeProtoService(t *testing.T) {
+
+	table, _ := loadTable(t, "orders", testTypesSql)
+	strContent := makeProtoService(&schema.Commander{}, table)
+	for _, v := range []string{
+		"service OrdersService {\n\trpc GetOrders(GetOrdersRequest) returns (OrdersDO);\n\trpc ListOrders(ListOrdersRequest) returns (ListOrdersResponse);\n" +
+			"\trpc CreateOrders(OrdersDO) returns (CreateOrdersResponse);\n\trpc UpdateOrders(UpdateOrdersRequest) returns (UpdateOrdersResponse);\n" +
+			"\trpc DeleteOrders(DeleteOrdersRequest) returns (DeleteOrdersResponse);\n}\n",
+		"message GetOrdersRequest {\n\tint64 order_id = 1;\n}\n",
+		"message ListOrdersResponse {\n\trepeated OrdersDO items = 1;\n\tint64 total = 2;\n}\n",
+		"message UpdateOrdersRequest {\n\tOrdersDO item = 1;\n",
+	} {
+		if !strings.Contains(strContent, v) {
+			t.Errorf("service must contain [%v]\n%v", v, strContent)
+		}
+	}
+
+	//no get/delete without primary key
+	strContent = makeProtoService(&schema.Commander{Without: []string{"order_id"}}, table)
+	if strings.Contains(strContent, "GetOrders") || strings.Contains(strContent, "DeleteOrders") || !strings.Contains(strContent, "rpc ListOrders") {
+		t.Errorf("service without get and delete expected\n%v", strContent)
+	}
+}
@@ -0,0 +1,180 @@
+package mysql
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"strings"
+
+	"github.com/civet148/gotools/log"
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+const (
+	COLUMN_KEY_PRIMARY = "PRI"
+	COLUMN_KEY_UNIQUE  = "UNI"
+	COLUMN_KEY_MULTI   = "MUL"
+)
+
+// export repository of table to file <table file>_dao.go, must be called after table structure made
+func exportTableDao(cmd *schema.Commander, table *schema.TableSchema) (err error) {
+
+	strFileName := strings.TrimSuffix(table.FileName, ".go") + "_dao.go"
+	var File *os.File
+	File, err = os.OpenFile(strFileName, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0)
+	if err != nil {
+		log.Errorf("open file [%v] error (%v)", strFileName, err.Error())
+		return
+	}
+	defer File.Close()
+	log.Infof("exporting table dao [%v] to file [%v]", table.TableName, strFileName)
+
+	var strContent string
+	strContent += fmt.Sprintf("// Code generated by db2go. DO NOT EDIT.\n")
+	strContent += fmt.Sprintf("package %v\n\n", cmd.PackageName)
+	strContent += schema.IMPORT_SQLCA + "\n\n"
+	strContent += makeDao(cmd, table)
+	_, err = File.WriteString(strContent)
+	return
+}
+
+func makeDao(cmd *schema.Commander, table *schema.TableSchema) (strContent string) {
+
+	var pk *schema.TableColumn
+	var indexes []string //unique/multiple index column constants for Cache(...)
+	for i, v := range table.Columns {
+		if schema.IsColumnIn(cmd.Without, table.TableName, v.Name) {
+			continue
+		}
+		switch v.Key {
+		case COLUMN_KEY_PRIMARY:
+			pk = &table.Columns[i]
+		case COLUMN_KEY_UNIQUE, COLUMN_KEY_MULTI:
+			indexes = append(indexes, makeColumnConst(cmd, table, v.Name))
+		}
+	}
+	if len(getPrimaryKeys(table)) > 1 {
+		pk = nil //composite primary key is not supported by orm
+	}
+	bCache := cmd.DaoCache
+	if hints, ok := cmd.CacheIndexes[table.TableName]; ok {
+		bCache = true
+		indexes = nil //cache indexes specified by config
+		for _, v := range hints {
+			indexes = append(indexes, makeColumnConst(cmd, table, v))
+		}
+	}
+
+	strTableName := schema.CamelCaseConvert(table.TableName)
+	strDao := fmt.Sprintf("%vDAO", strTableName)
+	strDo := table.StructName
+	var strCache, strPkCache string //.Cache(...) is emitted only if cache enabled, engine logs error on every call without cache instance
+	if bCache {
+		strCache = fmt.Sprintf(".Cache(%v)", strings.Join(indexes, ", "))
+		strPkCache = ".Cache()"
+	}
+
+	strContent += fmt.Sprintf("type %v struct {\n\tdb *sqlca.Engine\n}\n\n", strDao)
+	strContent += fmt.Sprintf("func New%v(db *sqlca.Engine) *%v {\n\treturn &%v{db: db}\n}\n\n", strDao, strDao, strDao)
+
+	strContent += fmt.Sprintf("// orm engine of table %v\n", table.TableName)
+	strContent += fmt.Sprintf("func (dao *%v) model(v interface{}) *sqlca.Engine {\n", strDao)
+	if pk != nil {
+		strContent += fmt.Sprintf("\treturn dao.db.Model().SetPkName(%vPkName).Model(v).Table(TableName%v)\n}\n\n", strTableName, strTableName)
+	} else {
+		strContent += fmt.Sprintf("\treturn dao.db.Model(v).Table(TableName%v)\n}\n\n", strTableName)
+	}
+
+	if pk != nil {
+		strPkType, _ := getColumnGoType(cmd, table, pk)
+		strArg := makeArgName(pk.Name)
+		strContent += fmt.Sprintf("// get record by primary key %v, return nil if not found\n", pk.Name)
+		strContent += fmt.Sprintf("func (dao *%v) GetBy%v(%v %v) (do *%v, err error) {\n", strDao, schema.CamelCaseConvert(pk.Name), strArg, strPkType, strDo)
+		strContent += fmt.Sprintf("\tdo = &%v{}\n\tvar count int64\n", strDo)
+		strContent += fmt.Sprintf("\tif count, err = dao.model(do).Id(%v)%v.Query(); err != nil || count == 0 {\n\t\treturn nil, err\n\t}\n\treturn\n}\n\n", strArg, strPkCache)
+	}
+
+	for _, v := range table.Columns {
+		if schema.IsColumnIn(cmd.Without, table.TableName, v.Name) || (v.Key != COLUMN_KEY_UNIQUE && v.Key != COLUMN_KEY_MULTI) {
+			continue
+		}
+		strColName := schema.CamelCaseConvert(v.Name)
+		strColType, _ := getColumnGoType(cmd, table, &v)
+		strArg := makeArgName(v.Name)
+		strField := strArg
+		if strings.HasPrefix(strColType, "*") { //nullable column as pointer
+			strColType = strings.TrimPrefix(strColType, "*")
+			strField = "&" + strArg
+		}
+		if v.Key == COLUMN_KEY_UNIQUE {
+			strContent += fmt.Sprintf("// get record by unique index %v, return nil if not found\n", v.Name)
+			strContent += fmt.Sprintf("func (dao *%v) GetBy%v(%v %v) (do *%v, err error) {\n", strDao, strColName, strArg, strColType, strDo)
+			strContent += fmt.Sprintf("\tdo = &%v{%v: %v}\n\tvar count int64\n", strDo, strColName, strField)
+			if bCache {
+				strContent += fmt.Sprintf("\tif count, err = dao.model(do).Cache(%v).Query(); err != nil || count == 0 {\n\t\treturn nil, err\n\t}\n\treturn\n}\n\n", makeColumnConst(cmd, table, v.Name))
+			} else {
+				strContent += fmt.Sprintf("\tif count, err = dao.model(do).Find(map[string]interface{}{%v: %v}); err != nil || count == 0 {\n\t\treturn nil, err\n\t}\n\treturn\n}\n\n", makeColumnConst(cmd, table, v.Name), strArg)
+			}
+		} else {
+			strContent += fmt.Sprintf("// get records by index %v\n", v.Name)
+			strContent += fmt.Sprintf("func (dao *%v) GetBy%v(%v %v) (dos []*%v, err error) {\n", strDao, strColName, strArg, strColType, strDo)
+			strContent += fmt.Sprintf("\t_, err = dao.model(&dos).Find(map[string]interface{}{%v: %v})\n\treturn\n}\n\n", makeColumnConst(cmd, table, v.Name), strArg)
+		}
+	}
+
+	strContent += "// insert record, return last insert id\n"
+	strContent += fmt.Sprintf("func (dao *%v) Insert(do *%v) (lastInsertId int64, err error) {\n", strDao, strDo)
+	strContent += fmt.Sprintf("\treturn dao.model(do)%v.Insert()\n}\n\n", strCache)
+
+	if pk != nil {
+		strContent += "// insert or update columns (all columns if not set) when primary key conflict\n"
+		strContent += fmt.Sprintf("func (dao *%v) Upsert(do *%v, columns ...string) (lastInsertId int64, err error) {\n", strDao, strDo)
+		strContent += fmt.Sprintf("\te := dao.model(do)%v.OnConflict(%vPkName)\n", strCache, strTableName)
+		strContent += "\tif len(columns) > 0 {\n\t\te = e.Select(columns...)\n\t}\n\treturn e.Upsert()\n}\n\n"
+
+		strContent += "// update columns (all columns if not set) by primary key\n"
+		strContent += fmt.Sprintf("func (dao *%v) UpdateColumns(do *%v, columns ...string) (rowsAffected int64, err error) {\n", strDao, strDo)
+		strContent += fmt.Sprintf("\te := dao.model(do)%v\n", strCache)
+		strContent += "\tif len(columns) > 0 {\n\t\te = e.Select(columns...)\n\t}\n\treturn e.Update()\n}\n\n"
+
+		strPkType, _ := getColumnGoType(cmd, table, pk)
+		strArg := makeArgName(pk.Name)
+		strContent += "// delete record by primary key\n"
+		strContent += fmt.Sprintf("func (dao *%v) DeleteBy%v(%v %v) (rowsAffected int64, err error) {\n", strDao, schema.CamelCaseConvert(pk.Name), strArg, strPkType)
+		strContent += fmt.Sprintf("\treturn dao.model(&%v{}).Id(%v)%v.Delete()\n}\n\n", strDo, strArg, strPkCache)
+	}
+
+	strContent += "// query records of page (start from 1) and total count\n"
+	strContent += fmt.Sprintf("func (dao *%v) ListPage(page, size int) (dos []*%v, total int64, err error) {\n", strDao, strDo)
+	strContent += "\tif page < 1 {\n\t\tpage = 1\n\t}\n"
+	strContent += "\tif total, err = dao.Count(); err != nil || total == 0 {\n\t\treturn\n\t}\n"
+	strContent += "\te := dao.model(&dos)\n"
+	if pk != nil {
+		strContent += fmt.Sprintf("\te = e.OrderBy(%vPkName)\n", strTableName)
+	}
+	strContent += "\t_, err = e.Limit(size).Offset((page - 1) * size).Query()\n\treturn\n}\n\n"
+
+	strContent += "// count records\n"
+	strContent += fmt.Sprintf("func (dao *%v) Count() (count int64, err error) {\n", strDao)
+	strContent += "\treturn dao.model(nil).Count()\n}\n"
+	return
+}
+
+func getPrimaryKeys(table *schema.TableSchema) (columns []string) {
+	for _, v := range table.Columns {
+		if v.Key == COLUMN_KEY_PRIMARY {
+			columns = append(columns, v.Name)
+		}
+	}
+	return
+}
+
+// argument name in lower camel case (eg. class_no -> classNo)
+func makeArgName(strColName string) string {
+	strName := schema.CamelCaseConvert(strColName)
+	strName = strings.ToLower(strName[:1]) + strName[1:]
+	if token.IsKeyword(strName) || schema.IsInSlice(strName, []string{"dao", "do", "dos", "e", "err", "count", "sqlca"}) {
+		strName += "_"
+	}
+	return strName
+}
@@ -0,0 +1,199 @@
+package mysql
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/civet148/sqlca/cmd/db2go/ddl"
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+const testTypesSql = "testdata/types.sql"
+const testUsersSql = "../../../test/test.sql"
+
+// export schemas of DDL files, returns relative file name -> content
+func exportDDL(t *testing.T, opt schema.Commander, sqlFiles ...string) (files map[string]string) {
+
+	parsed, err := ddl.ParseFiles("test", sqlFiles...)
+	if err != nil {
+		t.Fatalf("parse %v error (%v)", sqlFiles, err)
+	}
+	strOutDir := exportFixture(t, opt, func(cmd *schema.Commander) error {
+		return ExportSchemas(cmd, parsed)
+	})
+	defer os.RemoveAll(strOutDir)
+	return readOutput(t, strOutDir)
+}
+
+// export schemas of DDL files into a package under testdata (in this module, so it can import sqlca),
+// then run go test of the package with test files, skipped in short mode or without go command
+func testGeneratedPackage(t *testing.T, opt schema.Commander, sqlFiles []string, testFiles map[string]string) {
+
+	if testing.Short() {
+		t.Skip("skip building generated code in short mode")
+	}
+	strGo, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	parsed, err := ddl.ParseFiles("test", sqlFiles...)
+	if err != nil {
+		t.Fatalf("parse %v error (%v)", sqlFiles, err)
+	}
+	strOutDir, err := ioutil.TempDir("testdata", "db2go")
+	if err != nil {
+		t.Fatalf("make temp dir error (%v)", err)
+	}
+	defer os.RemoveAll(strOutDir)
+
+	cmd := opt
+	cmd.OutDir, cmd.PackageName = strOutDir, "models"
+	if err = ExportSchemas(&cmd, parsed); err != nil {
+		t.Fatalf("export error (%v)", err)
+	}
+	strPkgDir := filepath.Join(strOutDir, cmd.PackageName)
+	for k, v := range testFiles {
+		if err = ioutil.WriteFile(filepath.Join(strPkgDir, k), []byte(v), 0644); err != nil {
+			t.Fatalf("write test file [%v] error (%v)", k, err)
+		}
+	}
+	c := exec.Command(strGo, "test", "-count=1", "./"+filepath.ToSlash(strPkgDir))
+	if data, err := c.CombinedOutput(); err != nil {
+		t.Fatalf("go test generated package error (%v)\n%s", err, data)
+	}
+}
+
+func TestDaoOutput(t *testing.T) {
+
+	files := exportDDL(t, schema.Commander{Dao: true}, testTypesSql)
+	strDao := files["models/accounts_dao.go"]
+	for _, v := range []string{
+		"func NewAccountsDAO(db *sqlca.Engine) *AccountsDAO {",
+		"return dao.db.Model().SetPkName(AccountsPkName).Model(v).Table(TableNameAccounts)",
+		"func (dao *AccountsDAO) GetById(id uint64) (do *AccountsDO, err error) {",
+		"if count, err = dao.model(do).Id(id).Query(); err != nil || count == 0 {",
+		//unique index returns a record, multiple index returns records
+		"func (dao *AccountsDAO) GetByUuid(uuid string) (do *AccountsDO, err error) {",
+		"Find(map[string]interface{}{AccountsColumnUuid: uuid})",
+		"func (dao *AccountsDAO) GetByState(state AccountsState) (dos []*AccountsDO, err error) {",
+		"e := dao.model(do).OnConflict(AccountsPkName)",
+		"func (dao *AccountsDAO) UpdateColumns(do *AccountsDO, columns ...string) (rowsAffected int64, err error) {",
+		"func (dao *AccountsDAO) DeleteById(id uint64) (rowsAffected int64, err error) {",
+		"e = e.OrderBy(AccountsPkName)",
+		"func (dao *AccountsDAO) Count() (count int64, err error) {",
+	} {
+		if !strings.Contains(strDao, v) {
+			t.Errorf("accounts dao must contain [%v]\n%v", v, strDao)
+		}
+	}
+	if strings.Contains(strDao, ".Cache(") {
+		t.Errorf("no cache expected without dao cache option")
+	}
+	if _, ok := files["models/orders_dao.go"]; !ok {
+		t.Errorf("dao of every table expected, got %v", len(files))
+	}
+
+	files = exportDDL(t, schema.Commander{Dao: true, DaoCache: true}, testTypesSql)
+	strDao = files["models/accounts_dao.go"]
+	for _, v := range []string{
+		"Id(id).Cache().Query()",
+		"dao.model(do).Cache(AccountsColumnUuid).Query()",
+		"return dao.model(do).Cache(AccountsColumnUuid, AccountsColumnNick, AccountsColumnState).Insert()",
+	} {
+		if !strings.Contains(strDao, v) {
+			t.Errorf("accounts dao with cache must contain [%v]\n%v", v, strDao)
+		}
+	}
+
+	//cache indexes of config replace index columns
+	files = exportDDL(t, schema.Commander{Dao: true, CacheIndexes: map[string][]string{"accounts": {"uuid"}}}, testTypesSql)
+	if strDao = files["models/accounts_dao.go"]; !strings.Contains(strDao, "return dao.model(do).Cache(AccountsColumnUuid).Insert()") {
+		t.Errorf("cache index of config expected\n%v", strDao)
+	}
+}
+
+func TestMakeArgName(t *testing.T) {
+	for k, v := range map[string]string{"class_no": "classNo", "id": "id", "type": "type_", "err": "err_", "do": "do_"} {
+		if s := makeArgName(k); s != v {
+			t.Errorf("argument name of %v: %v expected, got %v", k, v, s)
+		}
+	}
+}
+
+const testDaoCode = `package models
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/civet148/sqlca"
+)
+
+func TestUsersDAO(t *testing.T) {
+
+	strDir, err := ioutil.TempDir("", "dao")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(strDir)
+	e := sqlca.NewEngine()
+	e.SetLogger(sqlca.NewNopLogger())
+	if e.Open("sqlite3://"+filepath.Join(strDir, "test.db")) == nil {
+		t.Fatal("open sqlite failed")
+	}
+	data, err := ioutil.ReadFile("../../test.sqlite.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = e.ExecRaw(string(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	dao := NewUsersDAO(e)
+	id, err := dao.Insert(&UsersDO{Name: "a", Phone: "123"})
+	if err != nil || id == 0 {
+		t.Fatalf("insert id %v error %v", id, err)
+	}
+	if do, err := dao.GetById(int32(id)); err != nil || do == nil || do.Name != "a" {
+		t.Fatalf("get by id %+v error %v", do, err)
+	}
+	if do, err := dao.GetById(999); err != nil || do != nil {
+		t.Errorf("nil of not found expected, got %+v error %v", do, err)
+	}
+	if dos, err := dao.GetByPhone("123"); err != nil || len(dos) != 1 {
+		t.Errorf("get by phone %v error %v", len(dos), err)
+	}
+	if _, err = dao.UpdateColumns(&UsersDO{Id: int32(id), Name: "b"}, UsersColumnName); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = dao.Insert(&UsersDO{Name: "c", Phone: "456"}); err != nil {
+		t.Fatal(err)
+	}
+	dos, total, err := dao.ListPage(2, 1)
+	if err != nil || total != 2 || len(dos) != 1 || dos[0].Name != "c" {
+		t.Errorf("page 2 of 2 records expected, got %v %v error %v", total, len(dos), err)
+	}
+	if dos, _, _ = dao.ListPage(1, 1); len(dos) != 1 || dos[0].Name != "b" {
+		t.Errorf("updated record of page 1 expected, got %+v", dos)
+	}
+	if _, err = dao.DeleteById(int32(id)); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := dao.Count(); err != nil || count != 1 {
+		t.Errorf("1 record expected, got %v error %v", count, err)
+	}
+}
+`
+
+// generated code of all options builds, and dao works on sqlite
+func TestDaoGeneratedCode(t *testing.T) {
+
+	testGeneratedPackage(t, schema.Commander{Dao: true, Validate: true}, []string{testUsersSql}, map[string]string{"dao_test.go": testDaoCode})
+	testGeneratedPackage(t, schema.Commander{Dao: true, DaoCache: true, NullAsPointer: true, TinyIntAsBool: true}, []string{testTypesSql, testUsersSql}, nil)
+}
@@ -0,0 +1,21 @@
+package mysql
+
+import (
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+// export tables parsed from DDL files (--sql) without database connection
+func ExportSchemas(cmd *schema.Commander, schemas []*schema.TableSchema) (err error) {
+
+	makeTableRelations(schemas)
+	if len(cmd.Templates) > 0 {
+		for _, v := range schemas {
+			fillTableSchema(cmd, v)
+		}
+		return schema.ExportTemplates(cmd, schemas)
+	}
+	if cmd.Protobuf {
+		return exportProtobuf(cmd, schemas)
+	}
+	return exportTableSchema(cmd, schemas)
+}
@@ -0,0 +1,235 @@
+package mysql
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/civet148/sqlca/cmd/db2go/ddl"
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+// testdata/*.information_schema.json are the output of QuerySchemas after the MySQL DDL is executed,
+// captured from go-mysql-server (MySQL 8.0 compatible), so the --sql path is compared with the live path
+// without a database. MySQL 8.0 differences from 5.7 DDL are normalized: no integer display width and
+// DEFAULT_GENERATED in EXTRA (only auto_increment of EXTRA drives generation).
+type ddlFixture struct {
+	strName     string
+	strDatabase string
+	strFixture  string
+	sqlFiles    []string
+	normalize   func(tables []*schema.TableSchema) //live schemas to what the DDL dialect can express
+}
+
+var ddlFixtures = []ddlFixture{
+	{strName: "mysql", strDatabase: "test", strFixture: "testdata/test.information_schema.json", sqlFiles: []string{"../../../test/test.sql"}},
+	{strName: "mysql types", strDatabase: "test_types", strFixture: "testdata/types.information_schema.json", sqlFiles: []string{"testdata/types.sql"}},
+	{strName: "postgres", strDatabase: "test", strFixture: "testdata/test.information_schema.json", sqlFiles: []string{"testdata/test.postgres.sql"},
+		normalize: func(tables []*schema.TableSchema) {
+			//postgres has no tinyint
+			forEachColumn(tables, func(col *schema.TableColumn) {
+				if col.DataType == "tinyint" {
+					col.DataType, col.ColumnType, col.Precision = "smallint", "smallint", 5
+				}
+			})
+		}},
+	{strName: "sqlite", strDatabase: "test", strFixture: "testdata/test.information_schema.json", sqlFiles: []string{"testdata/test.sqlite.sql"},
+		normalize: func(tables []*schema.TableSchema) {
+			//sqlite has no comments
+			for _, v := range tables {
+				v.TableComment = ""
+			}
+			forEachColumn(tables, func(col *schema.TableColumn) {
+				col.Comment = ""
+			})
+		}},
+}
+
+var regDisplayWidth = regexp.MustCompile(`^(tinyint|smallint|mediumint|int|bigint)\(\d+\)`)
+
+func forEachColumn(tables []*schema.TableSchema, fn func(col *schema.TableColumn)) {
+	for _, v := range tables {
+		for i := range v.Columns {
+			fn(&v.Columns[i])
+		}
+	}
+}
+
+func loadFixture(t *testing.T, f ddlFixture) (live, parsed []*schema.TableSchema) {
+
+	data, err := ioutil.ReadFile(f.strFixture)
+	if err != nil {
+		t.Fatalf("read fixture [%v] error (%v)", f.strFixture, err)
+	}
+	if err = json.Unmarshal(data, &live); err != nil {
+		t.Fatalf("unmarshal fixture [%v] error (%v)", f.strFixture, err)
+	}
+	if f.normalize != nil {
+		f.normalize(live)
+	}
+	if parsed, err = ddl.ParseFiles(f.strDatabase, f.sqlFiles...); err != nil {
+		t.Fatalf("parse %v error (%v)", f.sqlFiles, err)
+	}
+	return
+}
+
+// int(11) of mysql 5.7 is int of mysql 8.0, tinyint(1) is kept as the flag of bool
+func normalizeColumnType(strColumnType string) string {
+	if strings.HasPrefix(strColumnType, "tinyint(1)") {
+		return strColumnType
+	}
+	return regDisplayWidth.ReplaceAllString(strColumnType, "$1")
+}
+
+func TestParseDDLSchemas(t *testing.T) {
+
+	for _, f := range ddlFixtures {
+		live, parsed := loadFixture(t, f)
+		makeTableRelations(parsed)
+		if len(live) != len(parsed) {
+			t.Fatalf("%v: %v tables expected, got %v", f.strName, len(live), len(parsed))
+		}
+		for i, lt := range live {
+			pt := parsed[i]
+			if lt.SchemeName != pt.SchemeName || lt.TableName != pt.TableName || lt.TableComment != pt.TableComment {
+				t.Errorf("%v: table [%v.%v %q] expected, got [%v.%v %q]", f.strName,
+					lt.SchemeName, lt.TableName, lt.TableComment, pt.SchemeName, pt.TableName, pt.TableComment)
+				continue
+			}
+			if len(lt.Columns) != len(pt.Columns) {
+				t.Errorf("%v: table [%v] %v columns expected, got %v", f.strName, lt.TableName, len(lt.Columns), len(pt.Columns))
+				continue
+			}
+			for j, lc := range lt.Columns {
+				pc := pt.Columns[j]
+				checkColumn(t, f.strName+" "+lt.TableName, lc, pc)
+			}
+			if !reflect.DeepEqual(lt.Indexes, pt.Indexes) {
+				t.Errorf("%v: table [%v] indexes %+v expected, got %+v", f.strName, lt.TableName, lt.Indexes, pt.Indexes)
+			}
+			if !reflect.DeepEqual(lt.ForeignKeys, pt.ForeignKeys) {
+				t.Errorf("%v: table [%v] foreign keys %+v expected, got %+v", f.strName, lt.TableName, lt.ForeignKeys, pt.ForeignKeys)
+			}
+			if !reflect.DeepEqual(lt.Relations, pt.Relations) {
+				t.Errorf("%v: table [%v] relations %+v expected, got %+v", f.strName, lt.TableName, lt.Relations, pt.Relations)
+			}
+		}
+	}
+}
+
+// fields of column which drive the generated code
+func checkColumn(t *testing.T, strName string, lc, pc schema.TableColumn) {
+
+	type column struct {
+		Name, DataType, ColumnType, Key, Comment, IsNullable string
+		AutoIncr, HasDefault                                 bool
+		MaxLength, Precision, Scale                          int64
+	}
+	makeColumn := func(col schema.TableColumn) (c column) {
+		c = column{Name: col.Name, DataType: col.DataType, ColumnType: normalizeColumnType(col.ColumnType), Key: col.Key,
+			Comment: col.Comment, IsNullable: col.IsNullable, AutoIncr: strings.Contains(col.Extra, ddl.EXTRA_AUTO_INCR),
+			HasDefault: col.HasDefault}
+		//max length validates char/varchar, precision/scale of decimal makes int64 of proto
+		if col.DataType == "char" || col.DataType == "varchar" {
+			c.MaxLength = col.MaxLength
+		}
+		if col.DataType == "decimal" {
+			c.Precision, c.Scale = col.Precision, col.Scale
+		}
+		return
+	}
+	if l, p := makeColumn(lc), makeColumn(pc); l != p {
+		t.Errorf("%v: column %+v expected, got %+v", strName, l, p)
+	}
+}
+
+func TestParseDDLOutput(t *testing.T) {
+
+	var options = []schema.Commander{
+		{},
+		{Dao: true},
+		{Dao: true, DaoCache: true},
+		{TinyIntAsBool: true, NullAsPointer: true, Validate: true},
+		{DisableDecimal: true, OneFile: true},
+		{Protobuf: true},
+		{Protobuf: true, ProtoDecimal: PROTO_DECIMAL_INT64, ProtoService: true},
+	}
+	for _, f := range ddlFixtures {
+		for i, opt := range options {
+			live, parsed := loadFixture(t, f)
+			strLive := exportFixture(t, opt, func(cmd *schema.Commander) error {
+				if cmd.Protobuf {
+					return exportProtobuf(cmd, live)
+				}
+				return exportTableSchema(cmd, live)
+			})
+			strParsed := exportFixture(t, opt, func(cmd *schema.Commander) error {
+				return ExportSchemas(cmd, parsed)
+			})
+			liveFiles, parsedFiles := readOutput(t, strLive), readOutput(t, strParsed)
+			if len(liveFiles) != len(parsedFiles) {
+				t.Errorf("%v: options[%v] %v files expected, got %v", f.strName, i, len(liveFiles), len(parsedFiles))
+			}
+			for k, v := range liveFiles {
+				if parsedFiles[k] != v {
+					t.Errorf("%v: options[%v] file [%v] is different from live database\n%v\n---\n%v", f.strName, i, k, v, parsedFiles[k])
+				}
+			}
+			os.RemoveAll(strLive)
+			os.RemoveAll(strParsed)
+		}
+	}
+}
+
+func exportFixture(t *testing.T, opt schema.Commander, export func(cmd *schema.Commander) error) (strOutDir string) {
+
+	var err error
+	if strOutDir, err = ioutil.TempDir("", "db2go"); err != nil {
+		t.Fatalf("make temp dir error (%v)", err)
+	}
+	cmd := opt
+	cmd.OutDir, cmd.PackageName = strOutDir, "models"
+	if err = export(&cmd); err != nil {
+		t.Fatalf("export error (%v)", err)
+	}
+	return
+}
+
+// relative file name -> content
+func readOutput(t *testing.T, strOutDir string) (files map[string]string) {
+
+	files = make(map[string]string)
+	err := filepath.Walk(strOutDir, func(strPath string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := ioutil.ReadFile(strPath)
+		if err != nil {
+			return err
+		}
+		strRel, _ := filepath.Rel(strOutDir, strPath)
+		files[strRel] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("read output [%v] error (%v)", strOutDir, err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("no output in [%v]", strOutDir)
+	}
+	return
+}
+
+// databases without wildcard are exported as they are, no database query
+func TestQueryDatabases(t *testing.T) {
+
+	databases, err := QueryDatabases(nil, []string{"test", "chat_db", "test"})
+	if err != nil || !reflect.DeepEqual(databases, []string{"test", "chat_db"}) {
+		t.Errorf("[test chat_db] expected, got %v error %v", databases, err)
+	}
+}
@@ -0,0 +1,159 @@
+package mysql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/civet148/gotools/log"
+	"github.com/civet148/sqlca"
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+const INDEX_NAME_PRIMARY = "PRIMARY"
+
+type createTable struct {
+	Table string `db:"Table"`
+	DDL   string `db:"Create Table"`
+}
+
+// query tables with columns, indexes and foreign keys
+func QuerySchemas(cmd *schema.Commander, e *sqlca.Engine) (schemas []*schema.TableSchema, err error) {
+
+	if schemas, err = queryTables(cmd, e); err != nil {
+		return
+	}
+	for _, v := range schemas {
+		if err = queryTableColumns(cmd, e, v); err != nil {
+			return
+		}
+		if err = queryTableIndexes(cmd, e, v); err != nil {
+			return
+		}
+	}
+	if err = queryForeignKeys(cmd, e, schemas); err != nil {
+		return
+	}
+	makeTableRelations(schemas)
+	return
+}
+
+// DDL statements make target schema same as source (e is the source engine)
+// statements of dropping extra tables/columns/indexes are commented out, review before executing
+func MakeDiffDDL(e *sqlca.Engine, diffs []*schema.SchemaDiff) (ddl []string, err error) {
+
+	for _, d := range diffs {
+		var strDDL string
+		strTable := quote(d.Table)
+		switch d.Kind {
+		case schema.DIFF_KIND_TABLE:
+			switch d.Action {
+			case schema.DIFF_ACTION_MISSING:
+				var tables []*createTable
+				if _, err = e.Model(&tables).QueryRaw("SHOW CREATE TABLE `%v`.`%v`", d.FromTable.SchemeName, d.Table); err != nil {
+					log.Errorf("show create table [%v] error [%v]", d.Table, err.Error())
+					return
+				}
+				if len(tables) == 0 {
+					return nil, fmt.Errorf("show create table [%v] got nothing", d.Table)
+				}
+				strDDL = tables[0].DDL
+			case schema.DIFF_ACTION_EXTRA:
+				strDDL = fmt.Sprintf("-- DROP TABLE %v", strTable)
+			case schema.DIFF_ACTION_CHANGED:
+				strDDL = fmt.Sprintf("ALTER TABLE %v COMMENT %v", strTable, quoteValue(d.FromTable.TableComment))
+			}
+		case schema.DIFF_KIND_COLUMN:
+			switch d.Action {
+			case schema.DIFF_ACTION_MISSING:
+				strDDL = fmt.Sprintf("ALTER TABLE %v ADD COLUMN %v", strTable, makeColumnDefine(d.FromTable, d.FromColumn))
+			case schema.DIFF_ACTION_EXTRA:
+				strDDL = fmt.Sprintf("-- ALTER TABLE %v DROP COLUMN %v", strTable, quote(d.Name))
+			case schema.DIFF_ACTION_CHANGED:
+				strDDL = fmt.Sprintf("ALTER TABLE %v MODIFY COLUMN %v", strTable, makeColumnDefine(d.FromTable, d.FromColumn))
+			}
+		case schema.DIFF_KIND_INDEX:
+			switch d.Action {
+			case schema.DIFF_ACTION_MISSING:
+				strDDL = fmt.Sprintf("ALTER TABLE %v ADD %v", strTable, makeIndexDefine(d.FromIndex))
+			case schema.DIFF_ACTION_EXTRA:
+				strDDL = fmt.Sprintf("-- ALTER TABLE %v DROP %v", strTable, makeIndexDrop(d.ToIndex))
+			case schema.DIFF_ACTION_CHANGED:
+				strDDL = fmt.Sprintf("ALTER TABLE %v DROP %v, ADD %v", strTable, makeIndexDrop(d.ToIndex), makeIndexDefine(d.FromIndex))
+			}
+		}
+		ddl = append(ddl, strDDL+";")
+	}
+	return
+}
+
+// column definition placed after the previous column of source table
+func makeColumnDefine(table *schema.TableSchema, col *schema.TableColumn) string {
+
+	strDefine := fmt.Sprintf("%v %v", quote(col.Name), col.ColumnType)
+	if col.IsNullable == "NO" {
+		strDefine += " NOT NULL"
+	} else {
+		strDefine += " NULL"
+	}
+	if col.HasDefault {
+		strDefine += " DEFAULT " + makeDefaultValue(col)
+	}
+	if col.Extra != "" {
+		strDefine += " " + strings.Replace(strings.ToUpper(col.Extra), "DEFAULT_GENERATED", "", -1)
+	}
+	if col.Comment != "" {
+		strDefine += " COMMENT " + quoteValue(col.Comment)
+	}
+	strAfter := " FIRST"
+	for i, v := range table.Columns {
+		if v.Name == col.Name && i > 0 {
+			strAfter = " AFTER " + quote(table.Columns[i-1].Name)
+		}
+	}
+	return strings.TrimSpace(strDefine) + strAfter
+}
+
+func makeDefaultValue(col *schema.TableColumn) string {
+
+	strValue := col.Default
+	if _, err := strconv.ParseFloat(strValue, 64); err == nil && col.DataType != "varchar" && col.DataType != "char" {
+		return strValue
+	}
+	if strings.HasPrefix(strings.ToUpper(strValue), "CURRENT_TIMESTAMP") || strings.HasPrefix(strValue, "(") {
+		return strValue
+	}
+	return quoteValue(strValue)
+}
+
+func makeIndexDefine(idx *schema.TableIndex) string {
+
+	var columns []string
+	for _, v := range idx.Columns {
+		columns = append(columns, quote(v))
+	}
+	strColumns := strings.Join(columns, ",")
+	if idx.Name == INDEX_NAME_PRIMARY {
+		return fmt.Sprintf("PRIMARY KEY (%v)", strColumns)
+	}
+	if idx.IsUnique {
+		return fmt.Sprintf("UNIQUE INDEX %v (%v)", quote(idx.Name), strColumns)
+	}
+	return fmt.Sprintf("INDEX %v (%v)", quote(idx.Name), strColumns)
+}
+
+func makeIndexDrop(idx *schema.TableIndex) string {
+	if idx.Name == INDEX_NAME_PRIMARY {
+		return "PRIMARY KEY"
+	}
+	return "INDEX " + quote(idx.Name)
+}
+
+func quote(strName string) string {
+	return "`" + strName + "`"
+}
+
+func quoteValue(strValue string) string {
+	strValue = strings.Replace(strValue, `\`, `\\`, -1)
+	return "'" + strings.Replace(strValue, "'", "''", -1) + "'"
+}
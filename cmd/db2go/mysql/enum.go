@@ -0,0 +1,229 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+const (
+	DATA_TYPE_ENUM = "enum"
+	DATA_TYPE_SET  = "set"
+)
+
+const PROTO_ENUM_UNSPECIFIED = "Unspecified"
+
+const (
+	IMPORT_DRIVER  = "database/sql/driver"
+	IMPORT_FMT     = "fmt"
+	IMPORT_STRINGS = "strings"
+	IMPORT_SQLCA   = "github.com/civet148/sqlca"
+)
+
+// enum/set column with values parsed from COLUMN_TYPE
+func isEnumColumn(col *schema.TableColumn) bool {
+	return (col.DataType == DATA_TYPE_ENUM || col.DataType == DATA_TYPE_SET) && len(parseEnumValues(col.ColumnType)) > 0
+}
+
+// parse values of COLUMN_TYPE, eg. enum('male','female') -> [male female]
+func parseEnumValues(strColumnType string) (values []string) {
+
+	start := strings.Index(strColumnType, "(")
+	end := strings.LastIndex(strColumnType, ")")
+	if start < 0 || end < start {
+		return
+	}
+	s := strColumnType[start+1 : end]
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\'' {
+			continue
+		}
+		var sb strings.Builder
+		for i++; i < len(s); i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				sb.WriteByte(s[i])
+				continue
+			}
+			if s[i] == '\'' {
+				if i+1 < len(s) && s[i+1] == '\'' {
+					sb.WriteByte('\'')
+					i++
+					continue
+				}
+				break
+			}
+			sb.WriteByte(s[i])
+		}
+		values = append(values, sb.String())
+	}
+	return
+}
+
+// golang type name of enum/set column, eg. UsersSex
+func getEnumTypeName(table *schema.TableSchema, col *schema.TableColumn) string {
+	return schema.CamelCaseConvert(table.TableName) + schema.CamelCaseConvert(col.Name)
+}
+
+// identifiers of enum values in camel case, eg. male -> Male, in-progress -> InProgress, 2fa -> V2fa
+func makeEnumIdents(values []string) (idents []string) {
+
+	var dict = make(map[string]bool)
+	for i, v := range values {
+		var sb strings.Builder
+		for _, c := range strings.ToLower(v) {
+			if c >= 'a' && c <= 'z' || c >= '0' && c <= '9' {
+				sb.WriteRune(c)
+			} else {
+				sb.WriteByte('_')
+			}
+		}
+		strIdent := schema.CamelCaseConvert(strings.Trim(sb.String(), "_"))
+		if strIdent == "" {
+			strIdent = "Empty"
+		} else if strIdent[0] >= '0' && strIdent[0] <= '9' {
+			strIdent = "V" + strIdent
+		}
+		if dict[strIdent] {
+			strIdent = fmt.Sprintf("%v%v", strIdent, i)
+		}
+		dict[strIdent] = true
+		idents = append(idents, strIdent)
+	}
+	return
+}
+
+// imports of enum/set types in table
+func getEnumImports(cmd *schema.Commander, table *schema.TableSchema) (imports []string) {
+
+	var bEnum, bSet bool
+	for i, v := range table.Columns {
+		if schema.IsColumnIn(cmd.Without, table.TableName, v.Name) || !isEnumColumn(&table.Columns[i]) {
+			continue
+		}
+		bEnum = true
+		if v.DataType == DATA_TYPE_SET {
+			bSet = true
+		}
+	}
+	if bEnum {
+		imports = append(imports, IMPORT_DRIVER, IMPORT_FMT)
+	}
+	if bSet {
+		imports = append(imports, IMPORT_STRINGS)
+	}
+	return
+}
+
+// named string type of enum/set columns with constants, String(), IsValid() and sql.Scanner/driver.Valuer
+func makeEnumTypes(cmd *schema.Commander, table *schema.TableSchema) (strContent string) {
+
+	for i, v := range table.Columns {
+
+		col := &table.Columns[i]
+		if schema.IsColumnIn(cmd.Without, table.TableName, v.Name) || !isEnumColumn(col) {
+			continue
+		}
+		strType := getEnumTypeName(table, col)
+		values := parseEnumValues(v.ColumnType)
+		idents := makeEnumIdents(values)
+
+		var consts []string
+		strContent += fmt.Sprintf("// %v of column %v.%v\n", v.DataType, table.TableName, v.Name)
+		strContent += fmt.Sprintf("type %v string\n\n", strType)
+		strContent += "const (\n"
+		for j, s := range values {
+			strContent += fmt.Sprintf("\t%v%v %v = %q\n", strType, idents[j], strType, s)
+			consts = append(consts, strType+idents[j])
+		}
+		strContent += ")\n\n"
+
+		strContent += fmt.Sprintf("func (v %v) String() string { return string(v) }\n\n", strType)
+
+		if v.DataType == DATA_TYPE_SET {
+			strContent += "// check every member of set (separated by comma) is one of values\n"
+			strContent += fmt.Sprintf("func (v %v) IsValid() bool {\n", strType)
+			strContent += "\tif v == \"\" {\n\t\treturn true\n\t}\n"
+			strContent += "\tfor _, s := range strings.Split(string(v), \",\") {\n"
+			strContent += fmt.Sprintf("\t\tswitch %v(s) {\n\t\tcase %v:\n\t\tdefault:\n\t\t\treturn false\n\t\t}\n\t}\n\treturn true\n}\n\n", strType, strings.Join(consts, ", "))
+		} else {
+			strContent += "// check value is one of enum values\n"
+			strContent += fmt.Sprintf("func (v %v) IsValid() bool {\n", strType)
+			strContent += fmt.Sprintf("\tswitch v {\n\tcase %v:\n\t\treturn true\n\t}\n\treturn false\n}\n\n", strings.Join(consts, ", "))
+		}
+
+		strContent += "// implements sql.Scanner\n"
+		strContent += fmt.Sprintf("func (v *%v) Scan(src interface{}) error {\n", strType)
+		strContent += "\tswitch s := src.(type) {\n\tcase nil:\n\t\t*v = \"\"\n"
+		strContent += fmt.Sprintf("\tcase []byte:\n\t\t*v = %v(s)\n\tcase string:\n\t\t*v = %v(s)\n", strType, strType)
+		strContent += fmt.Sprintf("\tdefault:\n\t\treturn fmt.Errorf(\"can't scan %%T into %v\", src)\n\t}\n\treturn nil\n}\n\n", strType)
+
+		strContent += "// implements driver.Valuer, empty value is not checked\n"
+		strContent += fmt.Sprintf("func (v %v) Value() (driver.Value, error) {\n", strType)
+		strContent += fmt.Sprintf("\tif v != \"\" && !v.IsValid() {\n\t\treturn nil, fmt.Errorf(\"invalid %v value [%%v]\", string(v))\n\t}\n", strType)
+		strContent += "\treturn string(v), nil\n}\n\n"
+	}
+	return
+}
+
+// proto enum of enum/set columns, the first value is unspecified (zero value of proto3 enum)
+func makeProtoEnums(cmd *schema.Commander, table *schema.TableSchema) (strContent string) {
+
+	for i, v := range table.Columns {
+
+		col := &table.Columns[i]
+		if schema.IsColumnIn(cmd.Without, table.TableName, v.Name) || !isEnumColumn(col) {
+			continue
+		}
+		strType := getEnumTypeName(table, col)
+		strContent += fmt.Sprintf("enum %v {\n", strType)
+		strContent += fmt.Sprintf("	%v = 0;\n", getProtoEnumValue(table, col, PROTO_ENUM_UNSPECIFIED))
+		values := parseEnumValues(v.ColumnType)
+		for j, s := range makeEnumIdents(values) {
+			strContent += fmt.Sprintf("	%v = %v; //%v\n", getProtoEnumValue(table, col, s), j+1, values[j])
+		}
+		strContent += "}\n\n"
+	}
+	return
+}
+
+// proto enum value name of enum identifier, eg. InProgress -> ORDERS_STATE_IN_PROGRESS
+func getProtoEnumValue(table *schema.TableSchema, col *schema.TableColumn, strIdent string) string {
+	return strings.ToUpper(table.TableName + "_" + col.Name + "_" + toSnakeCase(strIdent))
+}
+
+// camel case to snake case, eg. InProgress -> in_progress
+func toSnakeCase(strIn string) string {
+	var sb strings.Builder
+	for i, c := range strIn {
+		if c >= 'A' && c <= 'Z' && i > 0 {
+			sb.WriteByte('_')
+		}
+		sb.WriteRune(c)
+	}
+	return strings.ToLower(sb.String())
+}
+
+// make import statement, eg. import "fmt" or import ( "fmt" "strings" )
+func makeImports(imports []string) string {
+
+	var dedup []string
+	for _, v := range imports {
+		if !schema.IsInSlice(v, dedup) {
+			dedup = append(dedup, v)
+		}
+	}
+	imports = dedup
+	switch len(imports) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("import %q\n\n", imports[0])
+	}
+	strContent := "import (\n"
+	for _, v := range imports {
+		strContent += fmt.Sprintf("\t%q\n", v)
+	}
+	return strContent + ")\n\n"
+}
@@ -0,0 +1,135 @@
+package mysql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/civet148/sqlca/cmd/db2go/ddl"
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+func TestParseEnumValues(t *testing.T) {
+
+	var cases = []struct {
+		strColumnType string
+		expect        []string
+	}{
+		{"enum('male','female')", []string{"male", "female"}},
+		{"set('a','b','c')", []string{"a", "b", "c"}},
+		{"enum('it''s','a,b','x\\'y','')", []string{"it's", "a,b", "x'y", ""}},
+		{"varchar(32)", nil},
+		{"enum", nil},
+	}
+	for _, v := range cases {
+		if s := parseEnumValues(v.strColumnType); !reflect.DeepEqual(s, v.expect) {
+			t.Errorf("values of %v: %q expected, got %q", v.strColumnType, v.expect, s)
+		}
+	}
+}
+
+func TestMakeEnumIdents(t *testing.T) {
+
+	values := []string{"male", "in-progress", "2fa", "", "Male", "a b"}
+	expect := []string{"Male", "InProgress", "V2fa", "Empty", "Male4", "AB"}
+	if s := makeEnumIdents(values); !reflect.DeepEqual(s, expect) {
+		t.Errorf("%v expected, got %v", expect, s)
+	}
+}
+
+func TestEnumColumnType(t *testing.T) {
+
+	table, _ := loadTable(t, "accounts", testTypesSql)
+	state, tags := findColumn(table, "state"), findColumn(table, "tags")
+	if !isEnumColumn(state) || !isEnumColumn(tags) || isEnumColumn(findColumn(table, "nick")) {
+		t.Fatalf("enum and set columns expected")
+	}
+
+	cmd := &schema.Commander{NullAsPointer: true}
+	if s, _ := getColumnGoType(cmd, table, state); s != "AccountsState" {
+		t.Errorf("go type AccountsState expected, got %v", s)
+	}
+	if s, _ := getColumnGoType(cmd, table, tags); s != "*AccountsTags" {
+		t.Errorf("nullable set as pointer expected, got %v", s)
+	}
+	cmd.TypeOverrides = map[string]string{"accounts.state": "string"}
+	if s, _ := getColumnGoType(cmd, table, state); s != "string" {
+		t.Errorf("type override first expected, got %v", s)
+	}
+	if s := getColumnProtoType(cmd, table, state); s != "AccountsState" {
+		t.Errorf("proto enum expected, got %v", s)
+	}
+	if s := getColumnProtoType(cmd, table, tags); s != "repeated AccountsTags" {
+		t.Errorf("repeated proto enum of set expected, got %v", s)
+	}
+
+	strContent := makeProtoEnums(&schema.Commander{}, table)
+	for _, v := range []string{
+		"enum AccountsState {\n\tACCOUNTS_STATE_UNSPECIFIED = 0;\n\tACCOUNTS_STATE_NORMAL = 1; //normal\n",
+		"enum AccountsTags {\n\tACCOUNTS_TAGS_UNSPECIFIED = 0;\n\tACCOUNTS_TAGS_A = 1; //a\n",
+	} {
+		if !strings.Contains(strContent, v) {
+			t.Errorf("proto enums must contain [%v]\n%v", v, strContent)
+		}
+	}
+	if s := makeProtoEnums(&schema.Commander{Without: []string{"state", "tags"}}, table); s != "" {
+		t.Errorf("no proto enum of excluded columns expected, got\n%v", s)
+	}
+}
+
+// postgres enum type of CREATE TYPE is mapped to enum column
+func TestPostgresEnumType(t *testing.T) {
+
+	tables, err := ddl.Parse("test", "CREATE TYPE mood AS ENUM ('sad', 'ok');\n"+
+		"CREATE TABLE persons (id SERIAL PRIMARY KEY, current_mood mood NOT NULL DEFAULT 'ok');")
+	if err != nil {
+		t.Fatalf("parse error (%v)", err)
+	}
+	col := findColumn(tables[0], "current_mood")
+	if col == nil || !isEnumColumn(col) || !reflect.DeepEqual(parseEnumValues(col.ColumnType), []string{"sad", "ok"}) {
+		t.Fatalf("enum column of postgres type expected, got %+v", col)
+	}
+	if s, _ := getColumnGoType(&schema.Commander{}, tables[0], col); s != "PersonsCurrentMood" {
+		t.Errorf("go type PersonsCurrentMood expected, got %v", s)
+	}
+}
+
+const testEnumCode = `package models
+
+import "testing"
+
+func TestEnumTypes(t *testing.T) {
+
+	if !AccountsStateFrozen.IsValid() || AccountsState("x").IsValid() || AccountsStateClosed.String() != "closed" {
+		t.Errorf("enum constants expected")
+	}
+	var state AccountsState
+	if err := state.Scan([]byte("normal")); err != nil || state != AccountsStateNormal {
+		t.Errorf("scan bytes got %v error %v", state, err)
+	}
+	if err := state.Scan(nil); err != nil || state != "" {
+		t.Errorf("scan nil got %v error %v", state, err)
+	}
+	if err := state.Scan(1); err == nil {
+		t.Errorf("scan int must fail")
+	}
+	if _, err := AccountsState("x").Value(); err == nil {
+		t.Errorf("value of invalid enum must fail")
+	}
+	if v, err := AccountsStateNormal.Value(); err != nil || v != "normal" {
+		t.Errorf("value got %v error %v", v, err)
+	}
+	if !AccountsTags("a,c").IsValid() || !AccountsTags("").IsValid() || AccountsTags("a,d").IsValid() {
+		t.Errorf("set members must be checked")
+	}
+	do := AccountsDO{State: AccountsStateFrozen}
+	if do.GetState() != AccountsStateFrozen {
+		t.Errorf("model field of enum type expected")
+	}
+}
+`
+
+// generated enum types work as sql.Scanner/driver.Valuer
+func TestEnumGeneratedCode(t *testing.T) {
+	testGeneratedPackage(t, schema.Commander{}, []string{testTypesSql}, map[string]string{"enum_test.go": testEnumCode})
+}
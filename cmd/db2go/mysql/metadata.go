@@ -0,0 +1,70 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+// column name constants, all columns slice, primary key name and index descriptors of table
+func makeMetadata(cmd *schema.Commander, table *schema.TableSchema) (strContent string) {
+
+	strTableName := schema.CamelCaseConvert(table.TableName)
+
+	var names []string
+	strContent += "const (\n"
+	for _, v := range table.Columns {
+		if schema.IsColumnIn(cmd.Without, table.TableName, v.Name) {
+			continue
+		}
+		strConst := makeColumnConst(cmd, table, v.Name)
+		strContent += fmt.Sprintf("\t%v = %q\n", strConst, v.Name)
+		names = append(names, strConst)
+	}
+	strContent += ")\n\n"
+
+	strContent += fmt.Sprintf("// all columns of table %v\n", table.TableName)
+	strContent += fmt.Sprintf("var %vColumns = []string{%v}\n\n", strTableName, strings.Join(names, ", "))
+
+	if pks := getPrimaryKeys(table); len(pks) == 1 {
+		strContent += fmt.Sprintf("// primary key of table %v\n", table.TableName)
+		strContent += fmt.Sprintf("const %vPkName = %v\n\n", strTableName, makeColumnConst(cmd, table, pks[0]))
+	}
+
+	if len(table.Indexes) == 0 {
+		return
+	}
+	var indexes []string
+	strContent += fmt.Sprintf("// indexes of table %v\n", table.TableName)
+	strContent += "var (\n"
+	for _, idx := range table.Indexes {
+		var columns []string
+		for _, v := range idx.Columns {
+			columns = append(columns, makeColumnConst(cmd, table, v))
+		}
+		strIndex := fmt.Sprintf("%vIndex%v", strTableName, makeIndexIdent(idx.Name))
+		strContent += fmt.Sprintf("\t%v = sqlca.TableIndex{Name: %q, Columns: []string{%v}, IsUnique: %v}\n",
+			strIndex, idx.Name, strings.Join(columns, ", "), idx.IsUnique)
+		indexes = append(indexes, strIndex)
+	}
+	strContent += ")\n\n"
+	strContent += fmt.Sprintf("var %vIndexes = []sqlca.TableIndex{%v}\n\n", strTableName, strings.Join(indexes, ", "))
+	return
+}
+
+// column name constant, eg. UsersColumnPhone (quoted name if column is excluded)
+func makeColumnConst(cmd *schema.Commander, table *schema.TableSchema, strColName string) string {
+	if schema.IsColumnIn(cmd.Without, table.TableName, strColName) {
+		return fmt.Sprintf("%q", strColName)
+	}
+	return fmt.Sprintf("%vColumn%v", schema.CamelCaseConvert(table.TableName), schema.CamelCaseConvert(strColName))
+}
+
+// index name in camel case, eg. PRIMARY -> Primary, idx_user_id -> IdxUserId
+func makeIndexIdent(strIndexName string) string {
+	if strIndexName == INDEX_NAME_PRIMARY {
+		return "Primary"
+	}
+	return schema.CamelCaseConvert(strIndexName)
+}
@@ -0,0 +1,66 @@
+package mysql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/civet148/sqlca/cmd/db2go/ddl"
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+func loadTable(t *testing.T, strTableName string, sqlFiles ...string) (table *schema.TableSchema, tables []*schema.TableSchema) {
+
+	var err error
+	if tables, err = ddl.ParseFiles("test", sqlFiles...); err != nil {
+		t.Fatalf("parse %v error (%v)", sqlFiles, err)
+	}
+	makeTableRelations(tables)
+	if table = findTable(tables, strTableName); table == nil {
+		t.Fatalf("table [%v] not found", strTableName)
+	}
+	return
+}
+
+func TestMakeMetadata(t *testing.T) {
+
+	table, _ := loadTable(t, "accounts", testTypesSql)
+	strContent := makeMetadata(&schema.Commander{Without: []string{"nick", "orders.price"}}, table)
+	for _, v := range []string{
+		"\tAccountsColumnId = \"id\"\n",
+		"\tAccountsColumnCreatedAt = \"created_at\"\n",
+		"var AccountsColumns = []string{AccountsColumnId, AccountsColumnUuid, AccountsColumnAge,",
+		"const AccountsPkName = AccountsColumnId\n",
+		"AccountsIndexPrimary = sqlca.TableIndex{Name: \"PRIMARY\", Columns: []string{AccountsColumnId}, IsUnique: true}",
+		"AccountsIndexState = sqlca.TableIndex{Name: \"state\", Columns: []string{AccountsColumnState}, IsUnique: false}",
+		//excluded column is quoted name in index
+		"AccountsIndexUNIQNICKLEVEL = sqlca.TableIndex{Name: \"UNIQ_NICK_LEVEL\", Columns: []string{\"nick\", AccountsColumnLevel}, IsUnique: true}",
+		"var AccountsIndexes = []sqlca.TableIndex{AccountsIndexPrimary, AccountsIndexState, AccountsIndexUNIQNICKLEVEL, AccountsIndexUuid}",
+	} {
+		if !strings.Contains(strContent, v) {
+			t.Errorf("metadata must contain [%v]\n%v", v, strContent)
+		}
+	}
+	if strings.Contains(strContent, "AccountsColumnNick") {
+		t.Errorf("excluded column must have no constant\n%v", strContent)
+	}
+}
+
+func TestMakeMetadataNoIndex(t *testing.T) {
+
+	table := &schema.TableSchema{TableName: "logs", Columns: []schema.TableColumn{{Name: "a", Key: COLUMN_KEY_PRIMARY}, {Name: "b", Key: COLUMN_KEY_PRIMARY}}}
+	strContent := makeMetadata(&schema.Commander{}, table)
+	if strings.Contains(strContent, "PkName") || strings.Contains(strContent, "Indexes") {
+		t.Errorf("no primary key name of composite key and no indexes expected\n%v", strContent)
+	}
+	if !strings.Contains(strContent, "var LogsColumns = []string{LogsColumnA, LogsColumnB}") {
+		t.Errorf("all columns expected\n%v", strContent)
+	}
+}
+
+func TestMakeIndexIdent(t *testing.T) {
+	for k, v := range map[string]string{"PRIMARY": "Primary", "idx_user_id": "IdxUserId", "phone": "Phone"} {
+		if s := makeIndexIdent(k); s != v {
+			t.Errorf("index identifier of %v: %v expected, got %v", k, v, s)
+		}
+	}
+}
@@ -8,49 +8,74 @@ import (
 	"strings"
 )
 
-func queryTables(cmd *schema.Commander, e *sqlca.Engine) (schemas []*schema.TableSchema, err error) {
-
-	var strQuery string
+var systemDatabases = []string{"information_schema", "mysql", "performance_schema", "sys"}
 
-	var tables []string
+func queryTables(cmd *schema.Commander, e *sqlca.Engine) (schemas []*schema.TableSchema, err error) {
 
+	var tables []*schema.TableSchema
 	if cmd.Database == "" {
 		err = fmt.Errorf("no database selected")
 		log.Error(err.Error())
 		return
 	}
-	var strDatabaseName = fmt.Sprintf("'%v'", cmd.Database)
 
-	log.Infof("ready to export tables [%v]", cmd.Tables)
-	for _, v := range cmd.Tables {
-		tables = append(tables, fmt.Sprintf("'%v'", v))
+	log.Infof("ready to export tables [%v] exclude [%v] of database [%v]", cmd.Tables, cmd.ExcludeTables, cmd.Database)
+	_, err = e.Model(&tables).QueryRaw("SELECT `TABLE_SCHEMA`, `TABLE_NAME`, `ENGINE`, `TABLE_COMMENT` FROM `INFORMATION_SCHEMA`.`TABLES` "+
+		"WHERE (`ENGINE`='MyISAM' OR `ENGINE` = 'InnoDB' OR `ENGINE` = 'TokuDB') AND `TABLE_SCHEMA` = '%v' ORDER BY TABLE_NAME", cmd.Database)
+	if err != nil {
+		log.Errorf("%s", err)
+		return
 	}
-
-	if len(tables) == 0 {
-		strQuery = fmt.Sprintf("SELECT `TABLE_SCHEMA`, `TABLE_NAME`, `ENGINE`, `TABLE_COMMENT` FROM `INFORMATION_SCHEMA`.`TABLES` "+
-			"WHERE (`ENGINE`='MyISAM' OR `ENGINE` = 'InnoDB' OR `ENGINE` = 'TokuDB') AND `TABLE_SCHEMA` IN (%v) ORDER BY TABLE_SCHEMA",
-			strDatabaseName)
-	} else {
-		strQuery = fmt.Sprintf("SELECT `TABLE_SCHEMA`, `TABLE_NAME`, `ENGINE`, `TABLE_COMMENT` FROM `INFORMATION_SCHEMA`.`TABLES` "+
-			"WHERE (`ENGINE`='MyISAM' OR `ENGINE` = 'InnoDB' OR `ENGINE` = 'TokuDB') AND `TABLE_SCHEMA` IN (%v) AND TABLE_NAME IN (%v) ORDER BY TABLE_SCHEMA",
-			strDatabaseName, strings.Join(tables, ","))
+	for _, v := range tables {
+		if schema.IsTableIncluded(cmd, v.TableName) {
+			schemas = append(schemas, v)
+		}
 	}
+	return
+}
 
-	_, err = e.Model(&schemas).QueryRaw(strQuery)
-	if err != nil {
+// databases to export, patterns with wildcard (eg. test_*) are matched with all databases except system databases
+func QueryDatabases(e *sqlca.Engine, patterns []string) (databases []string, err error) {
+
+	var bWildcard bool
+	for _, v := range patterns {
+		if schema.HasWildcard(v) {
+			bWildcard = true
+		} else if !schema.IsInSlice(v, databases) {
+			databases = append(databases, v)
+		}
+	}
+	if !bWildcard {
+		return
+	}
+	var names []string
+	if _, err = e.Model(&names).QueryRaw("SELECT `SCHEMA_NAME` FROM `INFORMATION_SCHEMA`.`SCHEMATA` ORDER BY `SCHEMA_NAME`"); err != nil {
 		log.Errorf("%s", err)
 		return
 	}
+	databases = nil
+	for _, v := range names {
+		if schema.IsInSlice(v, systemDatabases) && !schema.IsInSlice(v, patterns) {
+			continue
+		}
+		if schema.MatchPatterns(v, patterns) {
+			databases = append(databases, v)
+		}
+	}
 	return
 }
 
 func queryTableColumns(cmd *schema.Commander, e *sqlca.Engine, table *schema.TableSchema) (err error) {
 
 	/*
-	 SELECT `TABLE_NAME`, `COLUMN_NAME`, `DATA_TYPE`, `EXTRA`, `COLUMN_KEY`, `COLUMN_COMMENT` FROM `INFORMATION_SCHEMA`.`COLUMNS`
+	 SELECT `TABLE_NAME`, `COLUMN_NAME`, `DATA_TYPE`, `COLUMN_TYPE`, `EXTRA`, `COLUMN_KEY`, `COLUMN_COMMENT`, `IS_NULLABLE`, `CHARACTER_MAXIMUM_LENGTH`,
+	 IFNULL(`NUMERIC_PRECISION`, 0) AS `NUMERIC_PRECISION`, IFNULL(`NUMERIC_SCALE`, 0) AS `NUMERIC_SCALE`, (`COLUMN_DEFAULT` IS NOT NULL) AS `HAS_DEFAULT`, IFNULL(`COLUMN_DEFAULT`, '') AS `COLUMN_DEFAULT` FROM `INFORMATION_SCHEMA`.`COLUMNS`
 	 WHERE `TABLE_SCHEMA` = 'accounts' AND `TABLE_NAME` = 'users' ORDER BY ORDINAL_POSITION ASC
 	*/
-	_, err = e.Model(&table.Columns).QueryRaw("SELECT `TABLE_NAME`, `COLUMN_NAME`, `DATA_TYPE`, `EXTRA`, `COLUMN_KEY`, `COLUMN_COMMENT` FROM `INFORMATION_SCHEMA`.`COLUMNS` "+
+	_, err = e.Model(&table.Columns).QueryRaw("SELECT `TABLE_NAME`, `COLUMN_NAME`, `DATA_TYPE`, `COLUMN_TYPE`, `EXTRA`, `COLUMN_KEY`, `COLUMN_COMMENT`, "+
+		"`IS_NULLABLE`, `CHARACTER_MAXIMUM_LENGTH`, IFNULL(`NUMERIC_PRECISION`, 0) AS `NUMERIC_PRECISION`, IFNULL(`NUMERIC_SCALE`, 0) AS `NUMERIC_SCALE`, "+
+		"(`COLUMN_DEFAULT` IS NOT NULL) AS `HAS_DEFAULT`, IFNULL(`COLUMN_DEFAULT`, '') AS `COLUMN_DEFAULT` "+
+		"FROM `INFORMATION_SCHEMA`.`COLUMNS` "+
 		"WHERE `TABLE_SCHEMA` = '%v' AND `TABLE_NAME` = '%v' ORDER BY ORDINAL_POSITION ASC", table.SchemeName, table.TableName)
 	if err != nil {
 		log.Error(err.Error())
@@ -64,25 +89,61 @@ func queryTableColumns(cmd *schema.Commander, e *sqlca.Engine, table *schema.Tab
 	return
 }
 
+type indexColumn struct {
+	IndexName  string `db:"INDEX_NAME"`
+	ColumnName string `db:"COLUMN_NAME"`
+	NonUnique  int    `db:"NON_UNIQUE"`
+}
+
+func queryTableIndexes(cmd *schema.Commander, e *sqlca.Engine, table *schema.TableSchema) (err error) {
+
+	var columns []*indexColumn
+	_, err = e.Model(&columns).QueryRaw("SELECT `INDEX_NAME`, `COLUMN_NAME`, `NON_UNIQUE` FROM `INFORMATION_SCHEMA`.`STATISTICS` "+
+		"WHERE `TABLE_SCHEMA` = '%v' AND `TABLE_NAME` = '%v' ORDER BY `INDEX_NAME`, `SEQ_IN_INDEX`", table.SchemeName, table.TableName)
+	if err != nil {
+		log.Error(err.Error())
+		return
+	}
+	table.Indexes = nil
+	for _, v := range columns {
+		n := len(table.Indexes)
+		if n == 0 || table.Indexes[n-1].Name != v.IndexName {
+			table.Indexes = append(table.Indexes, schema.TableIndex{Name: v.IndexName, IsUnique: v.NonUnique == 0})
+			n++
+		}
+		table.Indexes[n-1].Columns = append(table.Indexes[n-1].Columns, v.ColumnName)
+	}
+	return
+}
+
 //将数据库字段类型转为go语言对应的数据类型
-func getGoColumnType(strTableName, strColName, strDataType, strColKey, strExtra string, disableDecimal bool) (strColType string, isDecimal bool) {
+func getGoColumnType(cmd *schema.Commander, strTableName string, col *schema.TableColumn) (strColType string, isDecimal bool) {
 
-	switch strDataType {
+	bUnsigned := strings.Contains(col.ColumnType, "unsigned")
+	switch col.DataType {
 	case "bigint":
 		strColType = "int64"
 	case "int", "integer", "mediumint":
 		strColType = "int32"
 	case "smallint":
 		strColType = "int16"
-	case "tinyint", "bit":
+	case "tinyint":
+		if cmd.TinyIntAsBool && isTinyIntBool(col) {
+			return "bool", false
+		}
+		strColType = "int8"
+	case "bit":
+		if getTypeWidth(col.ColumnType) > 8 {
+			return "uint64", false
+		}
 		strColType = "int8"
 	case "bool", "boolean":
 		strColType = "bool"
 	case "decimal":
-		if disableDecimal {
-			strColType = "float64"
-		} else {
+		if !cmd.DisableDecimal {
 			strColType = "sqlca.Decimal"
+		} else {
+			strColType = "float64"
 		}
 		isDecimal = true
 	case "real", "double", "float", "numeric":
@@ -94,33 +155,48 @@ func getGoColumnType(strTableName, strColName, strDataType, strColKey, strExtra
 	case "blob", "tinyblob", "mediumblob", "longblob", "binary", "varbinary", "json":
 		strColType = "string"
 	default:
-		{
-			err := fmt.Errorf("table [%v] column [%v] data type [%v] unsupport", strTableName, strColName, strDataType)
-			log.Errorf("%v", err.Error())
-			panic(err.Error())
-		}
+		warnUnsupportType(strTableName, col)
+		strColType = "string"
+	}
+	if bUnsigned && strings.HasPrefix(strColType, "int") {
+		strColType = "u" + strColType
 	}
 	return
 }
 
 //将数据库字段类型转为protobuf对应的数据类型
-func getProtoColumnType(strTableName, strColName, strDataType, strColKey, strExtra string, disableDecimal bool) (strColType string) {
+func getProtoColumnType(cmd *schema.Commander, strTableName string, col *schema.TableColumn) (strColType string) {
 
-	switch strDataType {
+	bUnsigned := strings.Contains(col.ColumnType, "unsigned")
+	switch col.DataType {
 	case "bigint":
 		strColType = "int64"
 	case "int", "integer", "mediumint":
 		strColType = "int32"
 	case "smallint":
 		strColType = "int32"
-	case "tinyint", "bit":
+	case "tinyint":
+		if cmd.TinyIntAsBool && isTinyIntBool(col) {
+			return "bool"
+		}
+		strColType = "int32"
+	case "bit":
+		if getTypeWidth(col.ColumnType) > 8 {
+			return "uint64"
+		}
 		strColType = "int32"
 	case "bool", "boolean":
 		strColType = "bool"
-	case "double", "decimal":
-		if disableDecimal {
+	case "decimal":
+		if cmd.ProtoDecimal == PROTO_DECIMAL_STRING {
+			strColType = "string"
+		} else if cmd.ProtoDecimal == PROTO_DECIMAL_INT64 && isDecimalInteger(col) {
+			strColType = "int64"
+		} else {
 			strColType = "double"
 		}
+	case "double":
+		strColType = "double"
 	case "real", "float", "numeric":
 		strColType = "float"
 	case "datetime", "year", "date", "time", "timestamp":
@@ -130,11 +206,40 @@ func getProtoColumnType(strTableName, strColName, strDataType, strColKey, strExt
 	case "blob", "tinyblob", "mediumblob", "longblob", "binary", "varbinary", "json":
 		strColType = "string"
 	default:
-		{
-			err := fmt.Errorf("table [%v] column [%v] data type [%v] unsupport", strTableName, strColName, strDataType)
-			log.Errorf("%v", err.Error())
-			panic(err.Error())
-		}
+		warnUnsupportType(strTableName, col)
+		strColType = "string"
+	}
+	if bUnsigned && strings.HasPrefix(strColType, "int") {
+		strColType = "u" + strColType
 	}
 	return
 }
+
+// tinyint(1) is used as boolean by mysql
+func isTinyIntBool(col *schema.TableColumn) bool {
+	return strings.HasPrefix(col.ColumnType, "tinyint(1)")
+}
+
+// decimal without scale fits in int64, eg. decimal(18,0)
+func isDecimalInteger(col *schema.TableColumn) bool {
+	return col.Scale == 0 && col.Precision > 0 && col.Precision <= 18
+}
+
+// width of column type, eg. bit(64) -> 64, returns 0 if not specified
+func getTypeWidth(strColumnType string) (width int) {
+	if idx := strings.Index(strColumnType, "("); idx >= 0 {
+		_, _ = fmt.Sscanf(strColumnType[idx+1:], "%d", &width)
+	}
+	return
+}
+
+var warnedColumns = make(map[string]bool)
+
+// warn once for each column of unsupported data type
+func warnUnsupportType(strTableName string, col *schema.TableColumn) {
+	strKey := strTableName + "." + col.Name
+	if !warnedColumns[strKey] {
+		warnedColumns[strKey] = true
+		log.Warnf("table [%v] column [%v] data type [%v] unsupport, use string instead", strTableName, col.Name, col.ColumnType)
+	}
+}
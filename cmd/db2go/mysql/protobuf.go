@@ -6,23 +6,25 @@ import (
 	"github.com/civet148/sqlca"
 	"github.com/civet148/sqlca/cmd/db2go/schema"
 	"os"
+	"strings"
 )
 
 func ExportProtobuf(cmd *schema.Commander, e *sqlca.Engine) (err error) {
 
 	var schemas []*schema.TableSchema
-	if schemas, err = queryTables(cmd, e); err != nil {
+	if schemas, err = QuerySchemas(cmd, e); err != nil {
 		log.Errorf(err.Error())
 		return
 	}
+	return exportProtobuf(cmd, schemas)
+}
+
+// export tables with columns and relations to proto file
+func exportProtobuf(cmd *schema.Commander, schemas []*schema.TableSchema) (err error) {
 
 	var file *os.File
 	strHead := makeProtoHead(cmd)
 	for i, v := range schemas {
-		if err = queryTableColumns(cmd, e, v); err != nil {
-			log.Error(err.Error())
-			return
-		}
 
 		var append bool
 		if i > 0 && cmd.OneFile {
@@ -67,17 +69,62 @@ func makeProtoHead(cmd *schema.Commander) (strContent string) {
 func makeProtoBody(cmd *schema.Commander, table *schema.TableSchema) (strContent string) {
 
 	strTableName := schema.CamelCaseConvert(table.TableName)
+	strContent += makeProtoEnums(cmd, table)
+	strContent += makeProtoRelationComments(table)
 	strContent += fmt.Sprintf("message %vDO {\n", strTableName)
 	for i, v := range table.Columns {
 
-		if schema.IsInSlice(v.Name, cmd.Without) {
+		if schema.IsColumnIn(cmd.Without, table.TableName, v.Name) {
 			continue
 		}
 		no := i + 1
 		strColName := v.Name
-		strColType := getProtoColumnType(table.TableName, v.Name, v.DataType, v.Key, v.Extra, true)
-		strContent += fmt.Sprintf("	%-10s %-22s = %-2d; //%v\n", strColType, strColName, no, v.Comment)
+		strColType := getColumnProtoType(cmd, table, &v)
+		strContent += fmt.Sprintf("	%-10s %-22s = %-2d; //%v\n", strColType, strColName, no, strings.TrimSpace(v.Comment+makeForeignKeyComment(table, v.Name)))
+	}
+	strContent += "}\n\n"
+	if cmd.ProtoService {
+		strContent += makeProtoService(cmd, table)
+	}
+	return
+}
+
+// gRPC CRUD service of table like DAO, Get/Delete by primary key are omitted if table has no single primary key
+func makeProtoService(cmd *schema.Commander, table *schema.TableSchema) (strContent string) {
+
+	strName := schema.CamelCaseConvert(table.TableName)
+	strDo := fmt.Sprintf("%vDO", strName)
+	var pk *schema.TableColumn
+	if keys := getPrimaryKeys(table); len(keys) == 1 && !schema.IsColumnIn(cmd.Without, table.TableName, keys[0]) {
+		for i, v := range table.Columns {
+			if v.Name == keys[0] {
+				pk = &table.Columns[i]
+			}
+		}
+	}
+
+	strContent += fmt.Sprintf("service %vService {\n", strName)
+	if pk != nil {
+		strContent += fmt.Sprintf("	rpc Get%v(Get%vRequest) returns (%v);\n", strName, strName, strDo)
+	}
+	strContent += fmt.Sprintf("	rpc List%v(List%vRequest) returns (List%vResponse);\n", strName, strName, strName)
+	strContent += fmt.Sprintf("	rpc Create%v(%v) returns (Create%vResponse);\n", strName, strDo, strName)
+	strContent += fmt.Sprintf("	rpc Update%v(Update%vRequest) returns (Update%vResponse);\n", strName, strName, strName)
+	if pk != nil {
+		strContent += fmt.Sprintf("	rpc Delete%v(Delete%vRequest) returns (Delete%vResponse);\n", strName, strName, strName)
 	}
 	strContent += "}\n\n"
+
+	if pk != nil {
+		strPkType := getColumnProtoType(cmd, table, pk)
+		strContent += fmt.Sprintf("message Get%vRequest {\n	%v %v = 1;\n}\n\n", strName, strPkType, pk.Name)
+		strContent += fmt.Sprintf("message Delete%vRequest {\n	%v %v = 1;\n}\n\n", strName, strPkType, pk.Name)
+		strContent += fmt.Sprintf("message Delete%vResponse {\n	int64 rows_affected = 1;\n}\n\n", strName)
+	}
+	strContent += fmt.Sprintf("message List%vRequest {\n	int32 page = 1; //page number from 1\n	int32 size = 2;\n}\n\n", strName)
+	strContent += fmt.Sprintf("message List%vResponse {\n	repeated %v items = 1;\n	int64 total = 2;\n}\n\n", strName, strDo)
+	strContent += fmt.Sprintf("message Create%vResponse {\n	int64 last_insert_id = 1;\n}\n\n", strName)
+	strContent += fmt.Sprintf("message Update%vRequest {\n	%v item = 1;\n	repeated string columns = 2; //columns to update, all columns if empty\n}\n\n", strName, strDo)
+	strContent += fmt.Sprintf("message Update%vResponse {\n	int64 rows_affected = 1;\n}\n\n", strName)
 	return
 }
@@ -0,0 +1,250 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/civet148/gotools/log"
+	"github.com/civet148/sqlca"
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+type foreignKeyColumn struct {
+	ConstraintName string `db:"CONSTRAINT_NAME"`
+	TableName      string `db:"TABLE_NAME"`
+	ColumnName     string `db:"COLUMN_NAME"`
+	RefTableName   string `db:"REFERENCED_TABLE_NAME"`
+	RefColumnName  string `db:"REFERENCED_COLUMN_NAME"`
+	UpdateRule     string `db:"UPDATE_RULE"`
+	DeleteRule     string `db:"DELETE_RULE"`
+}
+
+// query foreign keys of tables in the same database (foreign keys reference other databases are ignored)
+func queryForeignKeys(cmd *schema.Commander, e *sqlca.Engine, tables []*schema.TableSchema) (err error) {
+
+	var columns []*foreignKeyColumn
+	_, err = e.Model(&columns).QueryRaw("SELECT k.`CONSTRAINT_NAME`, k.`TABLE_NAME`, k.`COLUMN_NAME`, k.`REFERENCED_TABLE_NAME`, k.`REFERENCED_COLUMN_NAME`, "+
+		"r.`UPDATE_RULE`, r.`DELETE_RULE` FROM `INFORMATION_SCHEMA`.`KEY_COLUMN_USAGE` k "+
+		"JOIN `INFORMATION_SCHEMA`.`REFERENTIAL_CONSTRAINTS` r ON r.`CONSTRAINT_SCHEMA` = k.`CONSTRAINT_SCHEMA` AND r.`CONSTRAINT_NAME` = k.`CONSTRAINT_NAME` AND r.`TABLE_NAME` = k.`TABLE_NAME` "+
+		"WHERE k.`TABLE_SCHEMA` = '%v' AND k.`REFERENCED_TABLE_SCHEMA` = '%v' ORDER BY k.`TABLE_NAME`, k.`CONSTRAINT_NAME`, k.`ORDINAL_POSITION`", cmd.Database, cmd.Database)
+	if err != nil {
+		log.Error(err.Error())
+		return
+	}
+	var dict = make(map[string]*schema.TableSchema)
+	for _, v := range tables {
+		v.ForeignKeys = nil
+		dict[v.TableName] = v
+	}
+	for _, v := range columns {
+		table, ok := dict[v.TableName]
+		if !ok {
+			continue
+		}
+		n := len(table.ForeignKeys)
+		if n == 0 || table.ForeignKeys[n-1].Name != v.ConstraintName {
+			table.ForeignKeys = append(table.ForeignKeys, schema.ForeignKey{Name: v.ConstraintName, RefTable: v.RefTableName, OnDelete: v.DeleteRule, OnUpdate: v.UpdateRule})
+			n++
+		}
+		table.ForeignKeys[n-1].Columns = append(table.ForeignKeys[n-1].Columns, v.ColumnName)
+		table.ForeignKeys[n-1].RefColumns = append(table.ForeignKeys[n-1].RefColumns, v.RefColumnName)
+	}
+	return
+}
+
+// relationships by foreign keys between tables: belongs-to of referencing table,
+// has-one (foreign key columns are unique) or has-many of referenced table
+func makeTableRelations(tables []*schema.TableSchema) {
+
+	var dict = make(map[string]*schema.TableSchema)
+	for _, v := range tables {
+		v.Relations = nil
+		dict[v.TableName] = v
+	}
+	for _, v := range tables {
+		for _, fk := range v.ForeignKeys {
+			ref, ok := dict[fk.RefTable]
+			if !ok || len(fk.Columns) != len(fk.RefColumns) {
+				continue //referenced table is not exported
+			}
+			v.Relations = append(v.Relations, schema.Relation{Kind: sqlca.RELATION_BELONGS_TO, Name: fk.Name, Columns: fk.Columns, RefTable: ref.TableName, RefColumns: fk.RefColumns})
+			strKind := sqlca.RELATION_HAS_MANY
+			if isUniqueColumns(v, fk.Columns) {
+				strKind = sqlca.RELATION_HAS_ONE
+			}
+			ref.Relations = append(ref.Relations, schema.Relation{Kind: strKind, Name: fk.Name, Columns: fk.RefColumns, RefTable: v.TableName, RefColumns: fk.Columns})
+		}
+	}
+}
+
+// columns are primary key or unique index of table
+func isUniqueColumns(table *schema.TableSchema, columns []string) bool {
+	for _, v := range table.Indexes {
+		if v.IsUnique && strings.Join(v.Columns, ",") == strings.Join(columns, ",") {
+			return true
+		}
+	}
+	return false
+}
+
+// relation identifier, eg. ClassesBelongsToUsers, UsersHasManyClasses (with ByColumns suffix if ambiguous)
+func makeRelationIdent(table *schema.TableSchema, rel *schema.Relation) string {
+
+	strIdent := schema.CamelCaseConvert(table.TableName) + schema.CamelCaseConvert(strings.Replace(rel.Kind, "-", "_", -1)) + schema.CamelCaseConvert(rel.RefTable)
+	var count int
+	for _, v := range table.Relations {
+		if v.Kind == rel.Kind && v.RefTable == rel.RefTable {
+			count++
+		}
+	}
+	if count > 1 {
+		columns := rel.Columns
+		if rel.Kind != sqlca.RELATION_BELONGS_TO {
+			columns = rel.RefColumns //foreign key columns of related table
+		}
+		strIdent += "By"
+		for _, v := range columns {
+			strIdent += schema.CamelCaseConvert(v)
+		}
+	}
+	return strIdent
+}
+
+// relation descriptors of table
+func makeRelationMetadata(cmd *schema.Commander, table *schema.TableSchema, tables []*schema.TableSchema) (strContent string) {
+
+	if len(table.Relations) == 0 {
+		return
+	}
+	var relations []string
+	strTableName := schema.CamelCaseConvert(table.TableName)
+	strContent += fmt.Sprintf("// relationships of table %v by foreign keys\n", table.TableName)
+	strContent += "var (\n"
+	for i, v := range table.Relations {
+		ref := findTable(tables, v.RefTable)
+		var columns, refColumns []string
+		for _, c := range v.Columns {
+			columns = append(columns, makeColumnConst(cmd, table, c))
+		}
+		for _, c := range v.RefColumns {
+			refColumns = append(refColumns, makeColumnConst(cmd, ref, c))
+		}
+		strRelation := makeRelationIdent(table, &table.Relations[i])
+		strContent += fmt.Sprintf("\t%v = sqlca.TableRelation{Kind: %v, Name: %q, Table: TableName%v, Columns: []string{%v}, RefTable: TableName%v, RefColumns: []string{%v}}\n",
+			strRelation, getRelationKindConst(v.Kind), v.Name, strTableName, strings.Join(columns, ", "), schema.CamelCaseConvert(v.RefTable), strings.Join(refColumns, ", "))
+		relations = append(relations, strRelation)
+	}
+	strContent += ")\n\n"
+	strContent += fmt.Sprintf("var %vRelations = []sqlca.TableRelation{%v}\n\n", strTableName, strings.Join(relations, ", "))
+	return
+}
+
+func getRelationKindConst(strKind string) string {
+	switch strKind {
+	case sqlca.RELATION_HAS_ONE:
+		return "sqlca.RELATION_HAS_ONE"
+	case sqlca.RELATION_HAS_MANY:
+		return "sqlca.RELATION_HAS_MANY"
+	}
+	return "sqlca.RELATION_BELONGS_TO"
+}
+
+// loaders fetch related rows of DO slice by In(...) query, only single column relation of comparable type is supported
+// eg. LoadClassesBelongsToUsers(db, classes) returns map[users.id]*UsersDO
+func makeRelationLoaders(cmd *schema.Commander, table *schema.TableSchema, tables []*schema.TableSchema) (strContent string) {
+
+	for i, v := range table.Relations {
+
+		ref := findTable(tables, v.RefTable)
+		if len(v.Columns) != 1 || schema.IsColumnIn(cmd.Without, table.TableName, v.Columns[0]) || schema.IsColumnIn(cmd.Without, ref.TableName, v.RefColumns[0]) {
+			continue
+		}
+		col, refCol := findColumn(table, v.Columns[0]), findColumn(ref, v.RefColumns[0])
+		if col == nil || refCol == nil {
+			continue
+		}
+		strType, _ := getColumnGoType(cmd, table, col)
+		strRefType, _ := getColumnGoType(cmd, ref, refCol)
+		bPointer, bRefPointer := strings.HasPrefix(strType, "*"), strings.HasPrefix(strRefType, "*")
+		strType, strRefType = strings.TrimPrefix(strType, "*"), strings.TrimPrefix(strRefType, "*")
+		if strType != strRefType || strings.ContainsAny(strType, ".[") {
+			log.Warnf("relation [%v] of table [%v] column type [%v] -> [%v] is not comparable, loader is not generated", v.Name, table.TableName, strType, strRefType)
+			continue
+		}
+		strDo := fmt.Sprintf("%vDO", schema.CamelCaseConvert(table.TableName))
+		strRefDo := fmt.Sprintf("%vDO", schema.CamelCaseConvert(ref.TableName))
+		strField := "do." + schema.CamelCaseConvert(col.Name)
+		strRefField := "v." + schema.CamelCaseConvert(refCol.Name)
+		strValue := fmt.Sprintf("*%v", strRefDo)
+		if v.Kind == sqlca.RELATION_HAS_MANY {
+			strValue = fmt.Sprintf("[]*%v", strRefDo)
+		}
+
+		strContent += fmt.Sprintf("// load %v of %v (%v) by %v.%v -> %v.%v, key is %v.%v\n", ref.TableName, table.TableName, v.Kind,
+			table.TableName, col.Name, ref.TableName, refCol.Name, ref.TableName, refCol.Name)
+		strContent += fmt.Sprintf("func Load%v(db *sqlca.Engine, dos []*%v) (refs map[%v]%v, err error) {\n", makeRelationIdent(table, &table.Relations[i]), strDo, strType, strValue)
+		strContent += "\tvar args []interface{}\n\tfor _, do := range dos {\n"
+		if bPointer {
+			strContent += fmt.Sprintf("\t\tif %v != nil {\n\t\t\targs = append(args, *%v)\n\t\t}\n", strField, strField)
+		} else {
+			strContent += fmt.Sprintf("\t\targs = append(args, %v)\n", strField)
+		}
+		strContent += "\t}\n"
+		strContent += fmt.Sprintf("\trefs = make(map[%v]%v)\n\tif len(args) == 0 {\n\t\treturn\n\t}\n", strType, strValue)
+		strContent += fmt.Sprintf("\tvar rows []*%v\n", strRefDo)
+		strContent += fmt.Sprintf("\tif _, err = db.Model(&rows).Table(TableName%v).In(%v, args...).Query(); err != nil {\n\t\treturn nil, err\n\t}\n",
+			schema.CamelCaseConvert(ref.TableName), makeColumnConst(cmd, ref, refCol.Name))
+		strContent += "\tfor _, v := range rows {\n"
+		strKey := strRefField
+		if bRefPointer {
+			strContent += fmt.Sprintf("\t\tif %v == nil {\n\t\t\tcontinue\n\t\t}\n", strRefField)
+			strKey = "*" + strRefField
+		}
+		if v.Kind == sqlca.RELATION_HAS_MANY {
+			strContent += fmt.Sprintf("\t\trefs[%v] = append(refs[%v], v)\n", strKey, strKey)
+		} else {
+			strContent += fmt.Sprintf("\t\trefs[%v] = v\n", strKey)
+		}
+		strContent += "\t}\n\treturn\n}\n\n"
+	}
+	return
+}
+
+// proto comment of column references other table, eg. foreign key -> users.id
+func makeForeignKeyComment(table *schema.TableSchema, strColName string) (strComment string) {
+	for _, fk := range table.ForeignKeys {
+		for i, v := range fk.Columns {
+			if v == strColName && i < len(fk.RefColumns) {
+				strComment += fmt.Sprintf(" (foreign key -> %v.%v)", fk.RefTable, fk.RefColumns[i])
+			}
+		}
+	}
+	return
+}
+
+// proto comments of table relationships before message
+func makeProtoRelationComments(table *schema.TableSchema) (strContent string) {
+	for _, v := range table.Relations {
+		strContent += fmt.Sprintf("// %v %v: %v.(%v) -> %v.(%v)\n", v.Kind, v.RefTable, table.TableName, strings.Join(v.Columns, ","),
+			v.RefTable, strings.Join(v.RefColumns, ","))
+	}
+	return
+}
+
+func findTable(tables []*schema.TableSchema, strTableName string) *schema.TableSchema {
+	for _, v := range tables {
+		if v.TableName == strTableName {
+			return v
+		}
+	}
+	return nil
+}
+
+func findColumn(table *schema.TableSchema, strColName string) *schema.TableColumn {
+	for i, v := range table.Columns {
+		if v.Name == strColName {
+			return &table.Columns[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package mysql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/civet148/sqlca"
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+const testRelationsSql = "testdata/relations.sql"
+
+func TestMakeTableRelations(t *testing.T) {
+
+	users, tables := loadTable(t, "users", testRelationsSql)
+	expect := []schema.Relation{
+		{Kind: sqlca.RELATION_HAS_ONE, Name: "fk_profiles_user", Columns: []string{"id"}, RefTable: "profiles", RefColumns: []string{"user_id"}},
+		{Kind: sqlca.RELATION_HAS_MANY, Name: "fk_classes_teacher", Columns: []string{"id"}, RefTable: "classes", RefColumns: []string{"teacher_id"}},
+		{Kind: sqlca.RELATION_HAS_MANY, Name: "fk_classes_monitor", Columns: []string{"id"}, RefTable: "classes", RefColumns: []string{"monitor_id"}},
+	}
+	if !reflect.DeepEqual(users.Relations, expect) {
+		t.Errorf("%+v expected, got %+v", expect, users.Relations)
+	}
+	profiles := findTable(tables, "profiles")
+	expect = []schema.Relation{{Kind: sqlca.RELATION_BELONGS_TO, Name: "fk_profiles_user", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}}}
+	if !reflect.DeepEqual(profiles.Relations, expect) {
+		t.Errorf("%+v expected, got %+v", expect, profiles.Relations)
+	}
+
+	//relations are rebuilt, foreign keys of tables not exported are ignored
+	makeTableRelations(tables)
+	if len(users.Relations) != 3 {
+		t.Errorf("relations must not be duplicated, got %+v", users.Relations)
+	}
+	makeTableRelations([]*schema.TableSchema{profiles})
+	if len(profiles.Relations) != 0 {
+		t.Errorf("no relation of table not exported expected, got %+v", profiles.Relations)
+	}
+}
+
+func TestMakeRelationMetadata(t *testing.T) {
+
+	users, tables := loadTable(t, "users", testRelationsSql)
+	strContent := makeRelationMetadata(&schema.Commander{}, users, tables)
+	for _, v := range []string{
+		"UsersHasOneProfiles = sqlca.TableRelation{Kind: sqlca.RELATION_HAS_ONE, Name: \"fk_profiles_user\", Table: TableNameUsers, " +
+			"Columns: []string{UsersColumnId}, RefTable: TableNameProfiles, RefColumns: []string{ProfilesColumnUserId}}",
+		//ambiguous relations to the same table are named by foreign key columns
+		"UsersHasManyClassesByTeacherId = sqlca.TableRelation{Kind: sqlca.RELATION_HAS_MANY,",
+		"var UsersRelations = []sqlca.TableRelation{UsersHasOneProfiles, UsersHasManyClassesByTeacherId, UsersHasManyClassesByMonitorId}",
+	} {
+		if !strings.Contains(strContent, v) {
+			t.Errorf("relation metadata must contain [%v]\n%v", v, strContent)
+		}
+	}
+	if s := makeRelationMetadata(&schema.Commander{}, &schema.TableSchema{TableName: "logs"}, tables); s != "" {
+		t.Errorf("no metadata of table without relation expected, got\n%v", s)
+	}
+}
+
+func TestMakeRelationLoaders(t *testing.T) {
+
+	users, tables := loadTable(t, "users", testRelationsSql)
+	classes := findTable(tables, "classes")
+	cmd := &schema.Commander{NullAsPointer: true}
+	strContent := makeRelationLoaders(cmd, users, tables) + makeRelationLoaders(cmd, classes, tables)
+	for _, v := range []string{
+		"func LoadUsersHasOneProfiles(db *sqlca.Engine, dos []*UsersDO) (refs map[int32]*ProfilesDO, err error) {",
+		"func LoadUsersHasManyClassesByTeacherId(db *sqlca.Engine, dos []*UsersDO) (refs map[int32][]*ClassesDO, err error) {",
+		"\t\trefs[v.TeacherId] = append(refs[v.TeacherId], v)\n",
+		//nullable foreign key of pointer type
+		"\t\tif v.MonitorId == nil {\n\t\t\tcontinue\n\t\t}\n\t\trefs[*v.MonitorId] = append(refs[*v.MonitorId], v)\n",
+		"\t\tif do.MonitorId != nil {\n\t\t\targs = append(args, *do.MonitorId)\n\t\t}\n",
+		"db.Model(&rows).Table(TableNameUsers).In(UsersColumnId, args...).Query()",
+	} {
+		if !strings.Contains(strContent, v) {
+			t.Errorf("relation loaders must contain [%v]\n%v", v, strContent)
+		}
+	}
+
+	//no loader of excluded or type changed columns
+	cmd = &schema.Commander{Without: []string{"classes.teacher_id"}, TypeOverrides: map[string]string{"classes.monitor_id": "int64"}}
+	if s := makeRelationLoaders(cmd, classes, tables); s != "" {
+		t.Errorf("no loader expected, got\n%v", s)
+	}
+}
+
+func TestMakeProtoRelationComments(t *testing.T) {
+
+	_, tables := loadTable(t, "users", testRelationsSql)
+	classes := findTable(tables, "classes")
+	expect := "// belongs-to users: classes.(teacher_id) -> users.(id)\n// belongs-to users: classes.(monitor_id) -> users.(id)\n"
+	if s := makeProtoRelationComments(classes); s != expect {
+		t.Errorf("%v expected, got %v", expect, s)
+	}
+	if s := makeForeignKeyComment(classes, "monitor_id"); s != " (foreign key -> users.id)" {
+		t.Errorf("foreign key comment expected, got %q", s)
+	}
+	if s := makeForeignKeyComment(classes, "id"); s != "" {
+		t.Errorf("no comment of column without foreign key expected, got %q", s)
+	}
+}
+
+const testRelationCode = `package models
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/civet148/sqlca"
+)
+
+func TestRelationLoaders(t *testing.T) {
+
+	strDir, err := ioutil.TempDir("", "relation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(strDir)
+	db := sqlca.NewEngine()
+	db.SetLogger(sqlca.NewNopLogger())
+	if db.Open("sqlite3://"+filepath.Join(strDir, "test.db")) == nil {
+		t.Fatal("open sqlite failed")
+	}
+	data, err := ioutil.ReadFile("../../relations.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = db.ExecRaw(string(data) +
+		"INSERT INTO users (id, name) VALUES (1, 'a'), (2, 'b');" +
+		"INSERT INTO profiles (id, user_id, bio) VALUES (1, 2, 'x');" +
+		"INSERT INTO classes (id, teacher_id, monitor_id) VALUES (1, 1, 2), (2, 1, NULL), (3, 2, NULL);"); err != nil {
+		t.Fatal(err)
+	}
+
+	var users []*UsersDO
+	var classes []*ClassesDO
+	if _, err = db.Model(&users).Table(TableNameUsers).Query(); err != nil || len(users) != 2 {
+		t.Fatalf("query users %v error %v", len(users), err)
+	}
+	if _, err = db.Model(&classes).Table(TableNameClasses).OrderBy(ClassesColumnId).Query(); err != nil || len(classes) != 3 {
+		t.Fatalf("query classes %v error %v", len(classes), err)
+	}
+
+	profiles, err := LoadUsersHasOneProfiles(db, users)
+	if err != nil || len(profiles) != 1 || profiles[2].Bio != "x" {
+		t.Errorf("profile of user 2 expected, got %v error %v", profiles, err)
+	}
+	teaching, err := LoadUsersHasManyClassesByTeacherId(db, users)
+	if err != nil || len(teaching[1]) != 2 || len(teaching[2]) != 1 {
+		t.Errorf("2 classes of user 1 and 1 class of user 2 expected, got %v error %v", teaching, err)
+	}
+	monitors, err := LoadClassesBelongsToUsersByMonitorId(db, classes)
+	if err != nil || len(monitors) != 1 || monitors[2].Name != "b" {
+		t.Errorf("monitor user 2 expected, got %v error %v", monitors, err)
+	}
+	if refs, err := LoadClassesBelongsToUsersByMonitorId(db, classes[1:]); err != nil || len(refs) != 0 {
+		t.Errorf("no query of null foreign keys expected, got %v error %v", refs, err)
+	}
+}
+`
+
+// generated loaders query related rows on sqlite
+func TestRelationGeneratedCode(t *testing.T) {
+	testGeneratedPackage(t, schema.Commander{NullAsPointer: true}, []string{testRelationsSql}, map[string]string{"relation_test.go": testRelationCode})
+}
@@ -11,41 +11,15 @@ import (
 
 func ExportGoStruct(cmd *schema.Commander, e *sqlca.Engine) (err error) {
 
-	var strQuery string
 	var tableSchemas []*schema.TableSchema
-	var tableNames []string
-
-	if cmd.Database == "" {
-		err = fmt.Errorf("no database selected")
-		log.Error(err.Error())
+	if tableSchemas, err = QuerySchemas(cmd, e); err != nil {
 		return
 	}
-	var strDatabaseName = fmt.Sprintf("'%v'", cmd.Database)
-	log.Infof("ready to export tables [%v]", cmd.Tables)
-	for _, v := range cmd.Tables {
-		tableNames = append(tableNames, fmt.Sprintf("'%v'", v))
-	}
-
-	if len(tableNames) == 0 {
-		strQuery = fmt.Sprintf("SELECT `TABLE_SCHEMA`, `TABLE_NAME`, `ENGINE`, `TABLE_COMMENT` FROM `INFORMATION_SCHEMA`.`TABLES` "+
-			"WHERE (`ENGINE`='MyISAM' OR `ENGINE` = 'InnoDB' OR `ENGINE` = 'TokuDB') AND `TABLE_SCHEMA` IN (%v) ORDER BY TABLE_SCHEMA",
-			strDatabaseName)
-	} else {
-		strQuery = fmt.Sprintf("SELECT `TABLE_SCHEMA`, `TABLE_NAME`, `ENGINE`, `TABLE_COMMENT` FROM `INFORMATION_SCHEMA`.`TABLES` "+
-			"WHERE (`ENGINE`='MyISAM' OR `ENGINE` = 'InnoDB' OR `ENGINE` = 'TokuDB') AND `TABLE_SCHEMA` IN (%v) AND TABLE_NAME IN (%v) ORDER BY TABLE_SCHEMA",
-			strDatabaseName, strings.Join(tableNames, ","))
-	}
-
-	_, err = e.Model(&tableSchemas).QueryRaw(strQuery)
-	if err != nil {
-		log.Errorf("%s", err)
-		return
-	}
-
-	return exportTableSchema(cmd, e, tableSchemas)
+	return exportTableSchema(cmd, tableSchemas)
 }
 
-func exportTableSchema(cmd *schema.Commander, e *sqlca.Engine, tables []*schema.TableSchema) (err error) {
+// export tables with columns, indexes and relations
+func exportTableSchema(cmd *schema.Commander, tables []*schema.TableSchema) (err error) {
 
 	for _, v := range tables {
 
@@ -93,7 +67,7 @@ func exportTableSchema(cmd *schema.Commander, e *sqlca.Engine, tables []*schema.
 		}
 
 		v.FileName = fmt.Sprintf("%v/%v%v%v.go", v.SchemeDir, strPrefix, v.TableName, strSuffix)
-		if err = exportTableColumns(cmd, e, v); err != nil {
+		if err = exportTableColumns(cmd, v, tables); err != nil {
 			return
 		}
 	}
@@ -101,7 +75,7 @@ func exportTableSchema(cmd *schema.Commander, e *sqlca.Engine, tables []*schema.
 	return
 }
 
-func exportTableColumns(cmd *schema.Commander, e *sqlca.Engine, table *schema.TableSchema) (err error) {
+func exportTableColumns(cmd *schema.Commander, table *schema.TableSchema, tables []*schema.TableSchema) (err error) {
 
 	var File *os.File
 	File, err = os.OpenFile(table.FileName, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0)
@@ -120,36 +94,39 @@ func exportTableColumns(cmd *schema.Commander, e *sqlca.Engine, table *schema.Ta
 	//var TableCols []schema.TableColumnDB
 	//var TableColsGo []schema.TableColumnGo
 
-	/*
-	 SELECT `TABLE_NAME`, `COLUMN_NAME`, `DATA_TYPE`, `EXTRA`, `COLUMN_KEY`, `COLUMN_COMMENT` FROM `INFORMATION_SCHEMA`.`COLUMNS`
-	 WHERE `TABLE_SCHEMA` = 'accounts' AND `TABLE_NAME` = 'users' ORDER BY ORDINAL_POSITION ASC
-	*/
-	e.Model(&table.Columns).QueryRaw("SELECT `TABLE_NAME`, `COLUMN_NAME`, `DATA_TYPE`, `EXTRA`, `COLUMN_KEY`, `COLUMN_COMMENT` FROM `INFORMATION_SCHEMA`.`COLUMNS` "+
-		"WHERE `TABLE_SCHEMA` = '%v' AND `TABLE_NAME` = '%v' ORDER BY ORDINAL_POSITION ASC", table.SchemeName, table.TableName)
-
 	//write table name in camel case naming
 	strTableName := schema.CamelCaseConvert(table.TableName)
-	table.TableComment = schema.ReplaceCRLF(table.TableComment)
 	strContent += fmt.Sprintf("var TableName%v = \"%v\" //%v \n\n", strTableName, table.TableName, table.TableComment)
 
 	table.StructName = fmt.Sprintf("%vDO", strTableName)
 
-	for i, v := range table.Columns {
-		table.Columns[i].Comment = schema.ReplaceCRLF(v.Comment)
-	}
-	if haveDecimal(table, table.Columns) && !cmd.DisableDecimal {
-		strHead += schema.IMPORT_SQLCA + "\n\n" //根据数据库中是否存在decimal类型决定是否导入sqlca包
+	imports := append(getEnumImports(cmd, table), getTypeImports(cmd, table)...)
+	if (haveDecimal(cmd, table, table.Columns) && !cmd.DisableDecimal) || len(table.Indexes) > 0 || len(table.Relations) > 0 {
+		imports = append(imports, IMPORT_SQLCA) //根据数据库中是否存在decimal类型、索引或关联决定是否导入sqlca包
 	}
+	strHead += makeImports(imports)
+	strContent += makeMetadata(cmd, table)
+	strContent += makeRelationMetadata(cmd, table, tables)
+	strContent += makeEnumTypes(cmd, table)
 	strContent += makeTableStructure(cmd, table)
 	strContent += makeMethods(cmd, table)
+	strContent += makeRelationLoaders(cmd, table, tables)
 
 	_, _ = File.WriteString(strHead + strContent)
+	if cmd.Dao {
+		if err = exportTableDao(cmd, table); err != nil {
+			return
+		}
+	}
+	if cmd.PbPackage != "" {
+		return exportTableConverter(cmd, table)
+	}
 	return
 }
 
-func haveDecimal(table *schema.TableSchema, TableCols []schema.TableColumn) (ok bool) {
+func haveDecimal(cmd *schema.Commander, table *schema.TableSchema, TableCols []schema.TableColumn) (ok bool) {
 	for _, v := range TableCols {
-		_, ok = getGoColumnType(table.TableName, v.Name, v.DataType, v.Key, v.Extra, false)
+		_, ok = getColumnGoType(cmd, table, &v)
 		if ok {
 			break
 		}
@@ -161,13 +138,13 @@ func makeMethods(cmd *schema.Commander, table *schema.TableSchema) (strContent s
 
 	for _, v := range table.Columns { //添加结构体成员Get/Set方法
 
-		if schema.IsInSlice(v.Name, cmd.Without) {
+		if schema.IsColumnIn(cmd.Without, table.TableName, v.Name) {
 			continue
 		}
 		strColName := schema.CamelCaseConvert(v.Name)
-		strColType, _ := getGoColumnType(table.TableName, v.Name, v.DataType, v.Key, v.Extra, cmd.DisableDecimal)
+		strColType, _ := getColumnGoType(cmd, table, &v)
 		strContent += schema.MakeGetter(table.StructName, strColName, strColType)
-		if !schema.IsInSlice(v.Name, cmd.ReadOnly) {
+		if !schema.IsColumnIn(cmd.ReadOnly, table.TableName, v.Name) {
 			strContent += schema.MakeSetter(table.StructName, strColName, strColType)
 		}
 	}
@@ -180,21 +157,26 @@ func makeTableStructure(cmd *schema.Commander, table *schema.TableSchema) (strCo
 
 	for _, v := range table.Columns {
 
-		if schema.IsInSlice(v.Name, cmd.Without) {
+		if schema.IsColumnIn(cmd.Without, table.TableName, v.Name) {
 			continue
 		}
 
 		var tagValues []string
 		var strColType, strColName string
 		strColName = schema.CamelCaseConvert(v.Name)
-		strColType, _ = getGoColumnType(table.TableName, v.Name, v.DataType, v.Key, v.Extra, cmd.DisableDecimal)
+		strColType, _ = getColumnGoType(cmd, table, &v)
 
-		if schema.IsInSlice(v.Name, cmd.ReadOnly) {
+		if schema.IsColumnIn(cmd.ReadOnly, table.TableName, v.Name) {
 			tagValues = append(tagValues, fmt.Sprintf("%v:\"%v\"", sqlca.TAG_NAME_SQLCA, sqlca.SQLCA_TAG_VALUE_READ_ONLY))
 		}
-		for _, t := range cmd.Tags {
+		for _, t := range append(append([]string{}, cmd.Tags...), cmd.TableTags[table.TableName]...) {
 			tagValues = append(tagValues, fmt.Sprintf("%v:\"%v\"", t, v.Name))
 		}
+		if cmd.Validate {
+			if strRules := schema.MakeValidateRules(v, strColType); strRules != "" {
+				tagValues = append(tagValues, fmt.Sprintf("%v:\"%v\"", sqlca.TAG_NAME_VALIDATE, strRules))
+			}
+		}
 		//添加成员和标签
 		strContent += schema.MakeTags(strColName, strColType, v.Name, v.Comment, strings.Join(tagValues, " "))
 
@@ -0,0 +1,43 @@
+package mysql
+
+import (
+	"fmt"
+
+	"github.com/civet148/sqlca"
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+// render tables by user templates (--template)
+func ExportTemplate(cmd *schema.Commander, e *sqlca.Engine) (err error) {
+
+	var schemas []*schema.TableSchema
+	if schemas, err = QuerySchemas(cmd, e); err != nil {
+		return
+	}
+	for _, v := range schemas {
+		fillTableSchema(cmd, v)
+	}
+	return schema.ExportTemplates(cmd, schemas)
+}
+
+// fill struct name, primary key and golang/protobuf info of columns, the excluded columns are removed
+func fillTableSchema(cmd *schema.Commander, table *schema.TableSchema) {
+
+	table.StructName = fmt.Sprintf("%vDO", schema.CamelCaseConvert(table.TableName))
+	if pks := getPrimaryKeys(table); len(pks) == 1 {
+		table.PkName = pks[0]
+	}
+	var columns []schema.TableColumn
+	for _, v := range table.Columns {
+		if schema.IsColumnIn(cmd.Without, table.TableName, v.Name) {
+			continue
+		}
+		v.GoName = schema.CamelCaseConvert(v.Name)
+		v.GoType, v.IsDecimal = getColumnGoType(cmd, table, &v)
+		v.ProtoType = getColumnProtoType(cmd, table, &v)
+		v.IsPrimaryKey = v.Key == COLUMN_KEY_PRIMARY
+		v.IsReadOnly = schema.IsColumnIn(cmd.ReadOnly, table.TableName, v.Name)
+		columns = append(columns, v)
+	}
+	table.Columns = columns
+}
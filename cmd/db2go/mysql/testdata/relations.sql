@@ -0,0 +1,22 @@
+-- has-one, has-many and ambiguous belongs-to relations, both mysql and sqlite syntax
+
+CREATE TABLE `users` (
+  `id` INTEGER NOT NULL PRIMARY KEY,
+  `name` varchar(32) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE `profiles` (
+  `id` INTEGER NOT NULL PRIMARY KEY,
+  `user_id` INTEGER NOT NULL,
+  `bio` varchar(64) NOT NULL DEFAULT '',
+  UNIQUE (`user_id`),
+  CONSTRAINT `fk_profiles_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+);
+
+CREATE TABLE `classes` (
+  `id` INTEGER NOT NULL PRIMARY KEY,
+  `teacher_id` INTEGER NOT NULL,
+  `monitor_id` INTEGER DEFAULT NULL,
+  CONSTRAINT `fk_classes_teacher` FOREIGN KEY (`teacher_id`) REFERENCES `users` (`id`),
+  CONSTRAINT `fk_classes_monitor` FOREIGN KEY (`monitor_id`) REFERENCES `users` (`id`)
+);
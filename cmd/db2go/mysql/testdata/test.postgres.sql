@@ -0,0 +1,41 @@
+-- tables of test/test.sql in postgres syntax, postgres has no tinyint so smallint is used
+
+CREATE TABLE "classes" (
+  "id" serial NOT NULL,
+  "class_no" character varying(16) NOT NULL DEFAULT '',
+  "user_id" integer NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+COMMENT ON COLUMN "classes"."id" IS 'incr id';
+COMMENT ON COLUMN "classes"."class_no" IS 'class no';
+COMMENT ON COLUMN "classes"."user_id" IS 'student id';
+COMMENT ON COLUMN "classes"."created_at" IS 'create time';
+COMMENT ON COLUMN "classes"."updated_at" IS 'update time';
+
+CREATE TABLE "users" (
+  "id" serial NOT NULL,
+  "name" character varying(32) NOT NULL DEFAULT '',
+  "phone" character varying(32) NOT NULL,
+  "sex" smallint NOT NULL,
+  "email" character varying(64) NOT NULL DEFAULT '',
+  "disable" smallint NOT NULL DEFAULT 0,
+  "balance" numeric(10,2) NOT NULL DEFAULT 0.00,
+  "created_at" timestamp without time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp without time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT "users_pkey" PRIMARY KEY ("id")
+);
+
+CREATE INDEX "phone" ON "users" USING btree ("phone");
+
+COMMENT ON COLUMN "users"."id" IS 'auto inc id';
+COMMENT ON COLUMN "users"."name" IS 'user name';
+COMMENT ON COLUMN "users"."phone" IS 'phone number';
+COMMENT ON COLUMN "users"."sex" IS 'user sex';
+COMMENT ON COLUMN "users"."email" IS 'email';
+COMMENT ON COLUMN "users"."disable" IS 'disabled(0=false 1=true)';
+COMMENT ON COLUMN "users"."balance" IS 'balance of decimal';
+COMMENT ON COLUMN "users"."created_at" IS 'create time';
+COMMENT ON COLUMN "users"."updated_at" IS 'update time';
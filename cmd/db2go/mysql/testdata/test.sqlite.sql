@@ -0,0 +1,23 @@
+-- tables of test/test.sql in sqlite syntax, sqlite has no column comments
+
+CREATE TABLE IF NOT EXISTS "classes" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "class_no" varchar(16) NOT NULL DEFAULT '',
+  "user_id" INTEGER NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS "users" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "name" varchar(32) NOT NULL DEFAULT '',
+  "phone" varchar(32) NOT NULL,
+  "sex" tinyint(1) NOT NULL,
+  "email" varchar(64) NOT NULL DEFAULT '',
+  "disable" tinyint(1) NOT NULL DEFAULT 0,
+  "balance" decimal(10,2) NOT NULL DEFAULT 0.00,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "phone" ON "users" ("phone");
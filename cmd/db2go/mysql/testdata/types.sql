@@ -0,0 +1,38 @@
+-- column types, nullable columns, unique/composite indexes and foreign keys of mysql
+
+CREATE TABLE `accounts` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'account id',
+  `uuid` char(36) NOT NULL COMMENT 'account uuid',
+  `nick` varchar(64) DEFAULT NULL COMMENT 'nick name',
+  `age` tinyint(3) unsigned NOT NULL DEFAULT '0' COMMENT 'age',
+  `level` smallint(6) NOT NULL DEFAULT '1' COMMENT 'level',
+  `score` mediumint(8) unsigned DEFAULT NULL COMMENT 'score',
+  `points` int(10) unsigned NOT NULL DEFAULT '0' COMMENT 'points',
+  `is_admin` tinyint(1) NOT NULL DEFAULT '0' COMMENT 'admin(0=false 1=true)',
+  `amount` decimal(18,0) NOT NULL DEFAULT '0' COMMENT 'amount in cents',
+  `rate` decimal(10,4) DEFAULT NULL COMMENT 'rate',
+  `ratio` float DEFAULT NULL COMMENT 'ratio',
+  `weight` double NOT NULL DEFAULT '0' COMMENT 'weight',
+  `flags` bit(64) DEFAULT NULL COMMENT 'flags',
+  `state` enum('normal','frozen','closed') NOT NULL DEFAULT 'normal' COMMENT 'state',
+  `tags` set('a','b','c') DEFAULT NULL COMMENT 'tags',
+  `profile` json DEFAULT NULL COMMENT 'profile',
+  `avatar` blob COMMENT 'avatar',
+  `remark` text COMMENT 'remark',
+  `birthday` date DEFAULT NULL COMMENT 'birthday',
+  `login_at` datetime DEFAULT NULL COMMENT 'last login time',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'create time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uuid` (`uuid`),
+  UNIQUE KEY `UNIQ_NICK_LEVEL` (`nick`,`level`),
+  KEY `state` (`state`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `orders` (
+  `order_id` bigint(20) NOT NULL AUTO_INCREMENT COMMENT 'order id',
+  `account_id` bigint(20) unsigned NOT NULL COMMENT 'account id',
+  `price` decimal(10,2) NOT NULL COMMENT 'price',
+  PRIMARY KEY (`order_id`),
+  KEY `account_id` (`account_id`),
+  CONSTRAINT `fk_orders_account` FOREIGN KEY (`account_id`) REFERENCES `accounts` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
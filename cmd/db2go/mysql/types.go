@@ -0,0 +1,77 @@
+package mysql
+
+import (
+	"strings"
+
+	"github.com/civet148/sqlca/cmd/db2go/schema"
+)
+
+// golang type of column: type override > enum/set named type (eg. UsersSex) > mapped type (pointer if nullable and --null-pointer)
+func getColumnGoType(cmd *schema.Commander, table *schema.TableSchema, col *schema.TableColumn) (strColType string, isDecimal bool) {
+
+	if strOverride, ok := getTypeOverride(cmd, table.TableName, col.Name); ok {
+		strColType, _ = parseTypeOverride(strOverride)
+		return
+	}
+	if isEnumColumn(col) {
+		strColType = getEnumTypeName(table, col)
+	} else {
+		strColType, isDecimal = getGoColumnType(cmd, table.TableName, col)
+	}
+	if cmd.NullAsPointer && col.IsNullable == "YES" && col.Key != COLUMN_KEY_PRIMARY {
+		strColType = "*" + strColType
+	}
+	return
+}
+
+// protobuf type of column, enum column is proto enum and set column is repeated proto enum
+func getColumnProtoType(cmd *schema.Commander, table *schema.TableSchema, col *schema.TableColumn) (strColType string) {
+	if isEnumColumn(col) {
+		if col.DataType == DATA_TYPE_SET {
+			return "repeated " + getEnumTypeName(table, col)
+		}
+		return getEnumTypeName(table, col)
+	}
+	return getProtoColumnType(cmd, table.TableName, col)
+}
+
+// type override of column by key table.column or column
+func getTypeOverride(cmd *schema.Commander, strTableName, strColName string) (strType string, ok bool) {
+	if strType, ok = cmd.TypeOverrides[strTableName+"."+strColName]; ok {
+		return
+	}
+	strType, ok = cmd.TypeOverrides[strColName]
+	return
+}
+
+// parse type override to golang type and import path, eg. *encoding/json.RawMessage -> *json.RawMessage, encoding/json
+func parseTypeOverride(strOverride string) (strType, strImport string) {
+
+	strBase := strings.TrimLeft(strOverride, "*[]")
+	strPrefix := strOverride[:len(strOverride)-len(strBase)]
+	idxDot := strings.LastIndex(strBase, ".")
+	idxSlash := strings.LastIndex(strBase, "/")
+	if idxDot <= idxSlash {
+		return strOverride, "" //builtin type, eg. int64
+	}
+	if strBase[:idxDot] == "sqlca" {
+		return strOverride, IMPORT_SQLCA
+	}
+	return strPrefix + strBase[idxSlash+1:], strBase[:idxDot]
+}
+
+// imports of type overrides in table
+func getTypeImports(cmd *schema.Commander, table *schema.TableSchema) (imports []string) {
+
+	for _, v := range table.Columns {
+		if schema.IsColumnIn(cmd.Without, table.TableName, v.Name) {
+			continue
+		}
+		if strOverride, ok := getTypeOverride(cmd, table.TableName, v.Name); ok {
+			if _, strImport := parseTypeOverride(strOverride); strImport != "" && !schema.IsInSlice(strImport, imports) {
+				imports = append(imports, strImport)
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,171 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	DIFF_KIND_TABLE  = "table"
+	DIFF_KIND_COLUMN = "column"
+	DIFF_KIND_INDEX  = "index"
+)
+
+const (
+	DIFF_ACTION_MISSING = "missing" //exists in source but not in target
+	DIFF_ACTION_EXTRA   = "extra"   //exists in target but not in source
+	DIFF_ACTION_CHANGED = "changed" //exists in both but different
+)
+
+// difference of table/column/index from source to target
+type SchemaDiff struct {
+	Kind       string
+	Action     string
+	Table      string
+	Name       string   //column or index name
+	Details    []string //changed details, eg. type [varchar(32)] -> [varchar(64)]
+	FromTable  *TableSchema
+	ToTable    *TableSchema
+	FromColumn *TableColumn
+	ToColumn   *TableColumn
+	FromIndex  *TableIndex
+	ToIndex    *TableIndex
+}
+
+func (d *SchemaDiff) String() string {
+	strName := d.Table
+	if d.Kind != DIFF_KIND_TABLE {
+		strName = fmt.Sprintf("%v.%v", d.Table, d.Name)
+	}
+	strOut := fmt.Sprintf("[%-7s] %-6s %v", d.Action, d.Kind, strName)
+	if len(d.Details) > 0 {
+		strOut += " " + strings.Join(d.Details, ", ")
+	}
+	return strOut
+}
+
+// compare tables, columns, types, defaults, indexes and comments from source to target
+func DiffSchemas(from, to []*TableSchema) (diffs []*SchemaDiff) {
+
+	var toTables = make(map[string]*TableSchema)
+	for _, v := range to {
+		toTables[v.TableName] = v
+	}
+	var fromTables = make(map[string]*TableSchema)
+	for _, f := range from {
+		fromTables[f.TableName] = f
+		t, ok := toTables[f.TableName]
+		if !ok {
+			diffs = append(diffs, &SchemaDiff{Kind: DIFF_KIND_TABLE, Action: DIFF_ACTION_MISSING, Table: f.TableName, FromTable: f})
+			continue
+		}
+		if f.TableComment != t.TableComment {
+			diffs = append(diffs, &SchemaDiff{Kind: DIFF_KIND_TABLE, Action: DIFF_ACTION_CHANGED, Table: f.TableName, FromTable: f, ToTable: t,
+				Details: []string{diffDetail("comment", f.TableComment, t.TableComment)}})
+		}
+		diffs = append(diffs, diffColumns(f, t)...)
+		diffs = append(diffs, diffIndexes(f, t)...)
+	}
+	for _, t := range to {
+		if _, ok := fromTables[t.TableName]; !ok {
+			diffs = append(diffs, &SchemaDiff{Kind: DIFF_KIND_TABLE, Action: DIFF_ACTION_EXTRA, Table: t.TableName, ToTable: t})
+		}
+	}
+	return
+}
+
+func diffColumns(f, t *TableSchema) (diffs []*SchemaDiff) {
+
+	var toColumns = make(map[string]*TableColumn)
+	for i := range t.Columns {
+		toColumns[t.Columns[i].Name] = &t.Columns[i]
+	}
+	var fromColumns = make(map[string]bool)
+	for i := range f.Columns {
+		fc := &f.Columns[i]
+		fromColumns[fc.Name] = true
+		tc, ok := toColumns[fc.Name]
+		if !ok {
+			diffs = append(diffs, &SchemaDiff{Kind: DIFF_KIND_COLUMN, Action: DIFF_ACTION_MISSING, Table: f.TableName, Name: fc.Name,
+				FromTable: f, ToTable: t, FromColumn: fc})
+			continue
+		}
+		var details []string
+		if !strings.EqualFold(fc.ColumnType, tc.ColumnType) {
+			details = append(details, diffDetail("type", fc.ColumnType, tc.ColumnType))
+		}
+		if fc.IsNullable != tc.IsNullable {
+			details = append(details, diffDetail("nullable", fc.IsNullable, tc.IsNullable))
+		}
+		if fc.HasDefault != tc.HasDefault || fc.Default != tc.Default {
+			details = append(details, diffDetail("default", getDefaultText(fc), getDefaultText(tc)))
+		}
+		if !strings.EqualFold(fc.Extra, tc.Extra) {
+			details = append(details, diffDetail("extra", fc.Extra, tc.Extra))
+		}
+		if fc.Comment != tc.Comment {
+			details = append(details, diffDetail("comment", fc.Comment, tc.Comment))
+		}
+		if len(details) > 0 {
+			diffs = append(diffs, &SchemaDiff{Kind: DIFF_KIND_COLUMN, Action: DIFF_ACTION_CHANGED, Table: f.TableName, Name: fc.Name,
+				Details: details, FromTable: f, ToTable: t, FromColumn: fc, ToColumn: tc})
+		}
+	}
+	for i := range t.Columns {
+		if tc := &t.Columns[i]; !fromColumns[tc.Name] {
+			diffs = append(diffs, &SchemaDiff{Kind: DIFF_KIND_COLUMN, Action: DIFF_ACTION_EXTRA, Table: t.TableName, Name: tc.Name,
+				FromTable: f, ToTable: t, ToColumn: tc})
+		}
+	}
+	return
+}
+
+func diffIndexes(f, t *TableSchema) (diffs []*SchemaDiff) {
+
+	var toIndexes = make(map[string]*TableIndex)
+	for i := range t.Indexes {
+		toIndexes[t.Indexes[i].Name] = &t.Indexes[i]
+	}
+	var fromIndexes = make(map[string]bool)
+	for i := range f.Indexes {
+		fi := &f.Indexes[i]
+		fromIndexes[fi.Name] = true
+		ti, ok := toIndexes[fi.Name]
+		if !ok {
+			diffs = append(diffs, &SchemaDiff{Kind: DIFF_KIND_INDEX, Action: DIFF_ACTION_MISSING, Table: f.TableName, Name: fi.Name,
+				FromTable: f, ToTable: t, FromIndex: fi})
+			continue
+		}
+		var details []string
+		if strFrom, strTo := strings.Join(fi.Columns, ","), strings.Join(ti.Columns, ","); strFrom != strTo {
+			details = append(details, diffDetail("columns", strFrom, strTo))
+		}
+		if fi.IsUnique != ti.IsUnique {
+			details = append(details, diffDetail("unique", fmt.Sprintf("%v", fi.IsUnique), fmt.Sprintf("%v", ti.IsUnique)))
+		}
+		if len(details) > 0 {
+			diffs = append(diffs, &SchemaDiff{Kind: DIFF_KIND_INDEX, Action: DIFF_ACTION_CHANGED, Table: f.TableName, Name: fi.Name,
+				Details: details, FromTable: f, ToTable: t, FromIndex: fi, ToIndex: ti})
+		}
+	}
+	for i := range t.Indexes {
+		if ti := &t.Indexes[i]; !fromIndexes[ti.Name] {
+			diffs = append(diffs, &SchemaDiff{Kind: DIFF_KIND_INDEX, Action: DIFF_ACTION_EXTRA, Table: t.TableName, Name: ti.Name,
+				FromTable: f, ToTable: t, ToIndex: ti})
+		}
+	}
+	sort.SliceStable(diffs, func(i, j int) bool { return diffs[i].Name < diffs[j].Name })
+	return
+}
+
+func diffDetail(strName, strFrom, strTo string) string {
+	return fmt.Sprintf("%v [%v] -> [%v]", strName, strFrom, strTo)
+}
+
+func getDefaultText(col *TableColumn) string {
+	if !col.HasDefault {
+		return "NULL"
+	}
+	return col.Default
+}
@@ -3,7 +3,9 @@ package schema
 import (
 	"fmt"
 	"github.com/civet148/gotools/log"
+	"github.com/civet148/sqlca"
 	"os"
+	"path"
 	"strings"
 )
 
@@ -15,7 +17,8 @@ const (
 type Commander struct {
 	ConnUrl        string
 	Database       string
-	Tables         []string
+	Tables         []string //table name patterns, eg. user_*
+	ExcludeTables  []string //excluded table name patterns, eg. *_bak
 	Without        []string
 	ReadOnly       []string
 	Tags           []string
@@ -32,6 +35,19 @@ type Commander struct {
 	DisableDecimal bool
 	OneFile        bool
 	GogoOptions    []string
+	Validate       bool
+	Dao            bool
+	DaoCache       bool //dao uses Cache(...) of unique/index columns (or CacheIndexes of table)
+	Templates      []string
+	TinyIntAsBool  bool                //tinyint(1) as bool
+	NullAsPointer  bool                //nullable column as pointer type, eg. *string
+	TypeOverrides  map[string]string   //golang type of columns, key is table.column or column
+	TableTags      map[string][]string //extra struct tags of table
+	CacheIndexes   map[string][]string //cache index columns of table used by dao
+	SqlFiles       []string            //DDL files to generate from instead of database
+	PbPackage      string              //go import path of code generated from proto, outputs ToProto()/FromProto() of DO
+	ProtoDecimal   string              //proto type of decimal column, double (default), string or int64 (decimal(N,0) only)
+	ProtoService   bool                //output gRPC CRUD service of table into proto file
 }
 
 type TableSchema struct {
@@ -45,19 +61,54 @@ type TableSchema struct {
 	OutDir       string        `json:"OUT_DIR" db:"OUT_DIR"`             //output directory
 	FileName     string        `json:"FILE_NAME" db:"FILE_NAME"`         //output directory
 	Columns      []TableColumn `json:"TABLE_COLUMNS" db:"TABLE_COLUMNS"` //columns with database and golang
+	Indexes      []TableIndex  `json:"TABLE_INDEXES" db:"TABLE_INDEXES"` //indexes order by name
+	ForeignKeys  []ForeignKey  `json:"FOREIGN_KEYS" db:"FOREIGN_KEYS"`   //foreign keys reference other tables
+	Relations    []Relation    `json:"RELATIONS" db:"RELATIONS"`         //relationships by foreign keys of exported tables
 }
 
 type TableColumn struct {
 	Name         string `json:"COLUMN_NAME" db:"COLUMN_NAME"`
 	DataType     string `json:"DATA_TYPE" db:"DATA_TYPE"`
+	ColumnType   string `json:"COLUMN_TYPE" db:"COLUMN_TYPE"` //full column type, eg. varchar(32) or int(10) unsigned
 	Key          string `json:"COLUMN_KEY" db:"COLUMN_KEY"`
 	Extra        string `json:"EXTRA" db:"EXTRA"`
 	Comment      string `json:"COLUMN_COMMENT" db:"COLUMN_COMMENT"`
+	IsNullable   string `json:"IS_NULLABLE" db:"IS_NULLABLE"`                           //YES or NO
+	MaxLength    int64  `json:"CHARACTER_MAXIMUM_LENGTH" db:"CHARACTER_MAXIMUM_LENGTH"` //max length of char/varchar
+	Precision    int64  `json:"NUMERIC_PRECISION" db:"NUMERIC_PRECISION"`               //precision of numeric column
+	Scale        int64  `json:"NUMERIC_SCALE" db:"NUMERIC_SCALE"`                       //scale of numeric column
+	HasDefault   bool   `json:"HAS_DEFAULT" db:"HAS_DEFAULT"`                           //column has default value
+	Default      string `json:"COLUMN_DEFAULT" db:"COLUMN_DEFAULT"`                     //default value if HasDefault is true
 	IsPrimaryKey bool   // is primary key
 	IsDecimal    bool   // is decimal type
 	IsReadOnly   bool   // is read only
 	GoName       string //column name in golang
 	GoType       string //column type in golang
+	ProtoType    string //column type in protobuf
+}
+
+type TableIndex struct {
+	Name     string   `json:"INDEX_NAME" db:"INDEX_NAME"` //index name (PRIMARY for primary key)
+	Columns  []string `json:"COLUMNS" db:"COLUMNS"`       //columns order by sequence in index
+	IsUnique bool     `json:"IS_UNIQUE" db:"IS_UNIQUE"`   //unique index
+}
+
+type ForeignKey struct {
+	Name       string   `json:"CONSTRAINT_NAME" db:"CONSTRAINT_NAME"`             //constraint name
+	Columns    []string `json:"COLUMNS" db:"COLUMNS"`                             //columns order by sequence in foreign key
+	RefTable   string   `json:"REFERENCED_TABLE_NAME" db:"REFERENCED_TABLE_NAME"` //referenced table
+	RefColumns []string `json:"REFERENCED_COLUMNS" db:"REFERENCED_COLUMNS"`       //referenced columns (primary key of referenced table if empty in DDL)
+	OnDelete   string   `json:"DELETE_RULE" db:"DELETE_RULE"`                     //CASCADE/SET NULL/RESTRICT/NO ACTION
+	OnUpdate   string   `json:"UPDATE_RULE" db:"UPDATE_RULE"`                     //CASCADE/SET NULL/RESTRICT/NO ACTION
+}
+
+// relationship of table, Columns of table reference (belongs-to) or are referenced by (has-one/has-many) RefColumns of RefTable
+type Relation struct {
+	Kind       string   `json:"KIND" db:"KIND"`               //has-one, has-many or belongs-to
+	Name       string   `json:"NAME" db:"NAME"`               //foreign key name
+	Columns    []string `json:"COLUMNS" db:"COLUMNS"`         //columns of table
+	RefTable   string   `json:"REF_TABLE" db:"REF_TABLE"`     //related table
+	RefColumns []string `json:"REF_COLUMNS" db:"REF_COLUMNS"` //columns of related table
 }
 
 func IsInSlice(in string, s []string) bool {
@@ -69,12 +120,108 @@ func IsInSlice(in string, s []string) bool {
 	return false
 }
 
+// column is in list of column or table.column names
+func IsColumnIn(columns []string, strTable, strColumn string) bool {
+	return IsInSlice(strColumn, columns) || IsInSlice(strTable+"."+strColumn, columns)
+}
+
+// copy of commander to export a database, settings of database.table keys (eg. test.users.password) are
+// kept without database prefix if database matched or dropped if not, and take precedence over table keys
+func (cmd *Commander) UseDatabase(strDatabase string) *Commander {
+
+	c := *cmd
+	c.Database = strDatabase
+	c.Without = useDatabaseColumns(cmd.Without, strDatabase)
+	c.ReadOnly = useDatabaseColumns(cmd.ReadOnly, strDatabase)
+	c.TypeOverrides = make(map[string]string)
+	c.TableTags = make(map[string][]string)
+	c.CacheIndexes = make(map[string][]string)
+	for _, bQualified := range []bool{false, true} {
+		for k, v := range cmd.TypeOverrides {
+			if strKey, ok := useDatabaseKey(k, 2, strDatabase); ok && bQualified == (strKey != k) {
+				c.TypeOverrides[strKey] = v
+			}
+		}
+		for k, v := range cmd.TableTags {
+			if strKey, ok := useDatabaseKey(k, 1, strDatabase); ok && bQualified == (strKey != k) {
+				c.TableTags[strKey] = v
+			}
+		}
+		for k, v := range cmd.CacheIndexes {
+			if strKey, ok := useDatabaseKey(k, 1, strDatabase); ok && bQualified == (strKey != k) {
+				c.CacheIndexes[strKey] = v
+			}
+		}
+	}
+	return &c
+}
+
+func useDatabaseColumns(columns []string, strDatabase string) (s []string) {
+	for _, v := range columns {
+		if strKey, ok := useDatabaseKey(v, 2, strDatabase); ok {
+			s = append(s, strKey)
+		}
+	}
+	return
+}
+
+// trim database prefix of key qualified by database (key has n+1 parts), returns false if database not matched
+func useDatabaseKey(strKey string, n int, strDatabase string) (string, bool) {
+	if strings.Count(strKey, ".") != n {
+		return strKey, true
+	}
+	idx := strings.Index(strKey, ".")
+	if strKey[:idx] != strDatabase {
+		return "", false
+	}
+	return strKey[idx+1:], true
+}
+
+// name contains wildcard * or ?
+func HasWildcard(strName string) bool {
+	return strings.ContainsAny(strName, "*?")
+}
+
+// match name by patterns with wildcard * and ?, eg. user_*
+func MatchPatterns(strName string, patterns []string) bool {
+	for _, v := range patterns {
+		if ok, _ := path.Match(v, strName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// table matches --table patterns (all tables if empty) and not matches --exclude-table patterns
+func IsTableIncluded(cmd *Commander, strTable string) bool {
+	if len(cmd.Tables) > 0 && !MatchPatterns(strTable, cmd.Tables) {
+		return false
+	}
+	return !MatchPatterns(strTable, cmd.ExcludeTables)
+}
+
 func MakeTags(strColName, strColType, strTagValue, strComment string, strAppends string) string {
 	strComment = ReplaceCRLF(strComment)
 	return fmt.Sprintf("	%v %v `json:\"%v\" db:\"%v\" %v` //%v \n",
 		strColName, strColType, strTagValue, strTagValue, strAppends, strComment)
 }
 
+// make validate rules from column schema: NOT NULL without default -> required, char/varchar(N) -> max=N
+func MakeValidateRules(col TableColumn, strColType string) string {
+
+	var rules []string
+	if strColType != "string" {
+		return ""
+	}
+	if col.IsNullable == "NO" && !col.HasDefault && col.Key != "PRI" && !strings.Contains(col.Extra, "auto_increment") {
+		rules = append(rules, sqlca.VALIDATE_RULE_REQUIRED)
+	}
+	if (col.DataType == "varchar" || col.DataType == "char") && col.MaxLength > 0 {
+		rules = append(rules, fmt.Sprintf("%v=%v", sqlca.VALIDATE_RULE_MAX, col.MaxLength))
+	}
+	return strings.Join(rules, ",")
+}
+
 func MakeGetter(strStructName, strColName, strColType string) (strGetter string) {
 
 	return fmt.Sprintf("func (do *%v) Get%v() %v { return do.%v } \n", strStructName, strColName, strColType, strColName)
@@ -0,0 +1,74 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchPatterns(t *testing.T) {
+
+	var cases = []struct {
+		strName  string
+		patterns []string
+		expect   bool
+	}{
+		{"users", []string{"users"}, true},
+		{"user_roles", []string{"user_*"}, true},
+		{"users", []string{"user_*"}, false},
+		{"users_bak", []string{"classes", "*_bak"}, true},
+		{"t1", []string{"t?"}, true},
+		{"t10", []string{"t?"}, false},
+		{"users", nil, false},
+		{"users", []string{"[bad"}, false},
+	}
+	for _, v := range cases {
+		if ok := MatchPatterns(v.strName, v.patterns); ok != v.expect {
+			t.Errorf("%v matches %v: %v expected", v.strName, v.patterns, v.expect)
+		}
+	}
+	if !HasWildcard("test_*") || !HasWildcard("t?") || HasWildcard("test,chat_db") {
+		t.Errorf("wildcard * and ? expected")
+	}
+}
+
+func TestIsTableIncluded(t *testing.T) {
+
+	cmd := &Commander{}
+	if !IsTableIncluded(cmd, "users") {
+		t.Errorf("all tables included without patterns")
+	}
+	cmd = &Commander{Tables: []string{"user_*", "classes"}, ExcludeTables: []string{"*_bak"}}
+	for k, v := range map[string]bool{"user_roles": true, "classes": true, "user_roles_bak": false, "orders": false} {
+		if ok := IsTableIncluded(cmd, k); ok != v {
+			t.Errorf("table %v included: %v expected", k, v)
+		}
+	}
+}
+
+func TestUseDatabase(t *testing.T) {
+
+	cmd := &Commander{
+		Without:       []string{"password", "users.salt", "test.users.remark", "chat.users.nick"},
+		ReadOnly:      []string{"test.orders.price"},
+		TypeOverrides: map[string]string{"test.users.phone": "int64", "users.phone": "string", "chat.users.phone": "[]byte"},
+		TableTags:     map[string][]string{"users": {"yaml"}, "test.users": {"bson"}, "chat.messages": {"xml"}},
+		CacheIndexes:  map[string][]string{"chat.users": {"nick"}},
+	}
+	c := cmd.UseDatabase("test")
+	if c.Database != "test" || !reflect.DeepEqual(c.Without, []string{"password", "users.salt", "users.remark"}) || !reflect.DeepEqual(c.ReadOnly, []string{"orders.price"}) {
+		t.Errorf("columns of database test expected, got %v %v", c.Without, c.ReadOnly)
+	}
+	//database.table keys take precedence
+	if !reflect.DeepEqual(c.TypeOverrides, map[string]string{"users.phone": "int64"}) {
+		t.Errorf("type override of database expected, got %v", c.TypeOverrides)
+	}
+	if !reflect.DeepEqual(c.TableTags, map[string][]string{"users": {"bson"}}) || len(c.CacheIndexes) != 0 {
+		t.Errorf("tags of database expected, got %v %v", c.TableTags, c.CacheIndexes)
+	}
+	if c = cmd.UseDatabase("chat"); !reflect.DeepEqual(c.CacheIndexes, map[string][]string{"users": {"nick"}}) || c.TypeOverrides["users.phone"] != "[]byte" {
+		t.Errorf("settings of database chat expected, got %v %v", c.CacheIndexes, c.TypeOverrides)
+	}
+	if len(cmd.Without) != 4 || cmd.Database != "" {
+		t.Errorf("commander must not be changed")
+	}
+}
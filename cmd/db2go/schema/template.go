@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/civet148/gotools/log"
+)
+
+const TEMPLATE_FILE_SUFFIX = ".tmpl"
+
+// data of user template, Table is nil in one file mode
+type TemplateData struct {
+	Package  string         //package name
+	Database string         //database name
+	Table    *TableSchema   //current table
+	Tables   []*TableSchema //all tables
+}
+
+// helper functions of user template
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"camel":      CamelCaseConvert,
+		"lowerCamel": LowerCamelCase,
+		"goType":     func(col TableColumn) string { return col.GoType },
+		"protoType":  func(col TableColumn) string { return col.ProtoType },
+		"comment":    ReplaceCRLF,
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"join":       strings.Join,
+		"add":        func(a, b int) int { return a + b },
+	}
+}
+
+// render tables by user templates, output file name is <table>[_<name>].<ext> from template <name>.<ext>.tmpl or <ext>.tmpl
+// eg. template dao.go.tmpl outputs users_dao.go and go.tmpl outputs users.go
+func ExportTemplates(cmd *Commander, tables []*TableSchema) (err error) {
+
+	for _, strFile := range cmd.Templates {
+		var tpl *template.Template
+		if tpl, err = template.New(filepath.Base(strFile)).Funcs(TemplateFuncs()).ParseFiles(strFile); err != nil {
+			log.Errorf("parse template [%v] error [%v]", strFile, err.Error())
+			return
+		}
+		var c = *cmd
+		strName := strings.TrimSuffix(filepath.Base(strFile), TEMPLATE_FILE_SUFFIX)
+		strExt := strName
+		if idx := strings.LastIndex(strName, "."); idx >= 0 {
+			strExt = strName[idx+1:]
+			c.Suffix = strings.Trim(strings.Join([]string{cmd.Suffix, strName[:idx]}, "_"), "_")
+		}
+		if cmd.OneFile {
+			if len(tables) == 0 {
+				continue
+			}
+			data := &TemplateData{Package: cmd.PackageName, Database: cmd.Database, Tables: tables}
+			if err = renderTemplate(&c, tpl, tables[0], strExt, data); err != nil {
+				return
+			}
+			continue
+		}
+		for _, v := range tables {
+			data := &TemplateData{Package: cmd.PackageName, Database: cmd.Database, Table: v, Tables: tables}
+			if err = renderTemplate(&c, tpl, v, strExt, data); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+func renderTemplate(cmd *Commander, tpl *template.Template, table *TableSchema, strExt string, data *TemplateData) (err error) {
+
+	var buf bytes.Buffer
+	if err = tpl.Execute(&buf, data); err != nil {
+		log.Errorf("execute template [%v] table [%v] error [%v]", tpl.Name(), table.TableName, err.Error())
+		return
+	}
+	content := buf.Bytes()
+	if strExt == "go" {
+		if formatted, errFmt := format.Source(content); errFmt == nil {
+			content = formatted
+		} else {
+			log.Warnf("format template [%v] output error [%v]", tpl.Name(), errFmt.Error())
+		}
+	}
+	strFileName := table.FileName
+	file, err := CreateOutputFile(cmd, table, strExt, false)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	if _, err = file.Write(content); err != nil {
+		return fmt.Errorf("write file [%v] error [%v]", table.FileName, err.Error())
+	}
+	log.Infof("template [%v] output file [%v]", tpl.Name(), table.FileName)
+	table.FileName = strFileName
+	return
+}
+
+// lower camel case (eg. class_no -> classNo)
+func LowerCamelCase(strIn string) string {
+	strOut := CamelCaseConvert(strIn)
+	if strOut == "" {
+		return strOut
+	}
+	return strings.ToLower(strOut[:1]) + strOut[1:]
+}
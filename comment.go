@@ -0,0 +1,113 @@
+package sqlca
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	SQL_COMMENT_KEY_APP      = "app"
+	SQL_COMMENT_KEY_CALLER   = "caller"
+	SQL_COMMENT_KEY_FILE     = "file"
+	SQL_COMMENT_KEY_TRACE_ID = "trace_id"
+)
+
+type SqlCommentOptions struct {
+	App         string                           // application or service name
+	Tags        map[string]string                // static tags appended to every comment
+	TraceIdFunc func(ctx context.Context) string // get trace id from request context, default TraceIdFromContext
+}
+
+type traceIdKey struct{}
+
+// save trace id to context, engine gets it by TraceIdFromContext when sql comment enabled
+func ContextWithTraceId(ctx context.Context, strTraceId string) context.Context {
+	return context.WithValue(ctx, traceIdKey{}, strTraceId)
+}
+
+// get trace id which saved by ContextWithTraceId
+func TraceIdFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if v, ok := ctx.Value(traceIdKey{}).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// set request context for the next statement(s), trace id of sql comment read from it
+func (e *Engine) Context(ctx context.Context) *Engine {
+	c := e.copy()
+	c.ctx = ctx
+	return c
+}
+
+// prepend a comment like /* app='x',caller='pkg.Func',file='y.go%3A42',trace_id='...' */ to every generated and raw statement
+// this function must calls before Model()
+func (e *Engine) SetSqlComment(options *SqlCommentOptions) *Engine {
+
+	var opts SqlCommentOptions
+	if options != nil {
+		opts = *options
+	}
+	if opts.TraceIdFunc == nil {
+		opts.TraceIdFunc = TraceIdFromContext
+	}
+	return e.Use(func(stmt *Statement, next Invoker) error {
+		stmt.SQL = makeSqlComment(stmt.Context, &opts) + " " + stmt.SQL
+		return next(stmt)
+	})
+}
+
+func makeSqlComment(ctx context.Context, opts *SqlCommentOptions) string {
+
+	var kvs []string
+	if opts.App != "" {
+		kvs = append(kvs, fmtSqlCommentKV(SQL_COMMENT_KEY_APP, opts.App))
+	}
+	strFile, strFunc, nLine := getOuterCaller()
+	if strFunc != "" {
+		kvs = append(kvs, fmtSqlCommentKV(SQL_COMMENT_KEY_CALLER, strFunc))
+		kvs = append(kvs, fmtSqlCommentKV(SQL_COMMENT_KEY_FILE, fmt.Sprintf("%v:%v", filepath.Base(strFile), nLine)))
+	}
+	if strTraceId := opts.TraceIdFunc(ctx); strTraceId != "" {
+		kvs = append(kvs, fmtSqlCommentKV(SQL_COMMENT_KEY_TRACE_ID, strTraceId))
+	}
+	var keys []string
+	for k := range opts.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		kvs = append(kvs, fmtSqlCommentKV(k, opts.Tags[k]))
+	}
+	return fmt.Sprintf("/* %v */", strings.Join(kvs, ","))
+}
+
+// key and value are url encoded and value is quoted like sqlcommenter, eg. file='user.go%3A42'
+// encoded text has no * / ' , = or line breaks, so it can't close the comment or break key=value pairs
+func fmtSqlCommentKV(strKey, strValue string) string {
+	return escapeSqlComment(strKey) + "='" + escapeSqlComment(strValue) + "'"
+}
+
+func escapeSqlComment(strText string) string {
+	return strings.Replace(url.QueryEscape(strText), "+", "%20", -1)
+}
+
+// remove leading /* ... */ comments of sql
+func trimSqlComment(strSql string) string {
+	strSql = strings.TrimSpace(strSql)
+	for strings.HasPrefix(strSql, "/*") {
+		idx := strings.Index(strSql, "*/")
+		if idx == -1 {
+			break
+		}
+		strSql = strings.TrimSpace(strSql[idx+2:])
+	}
+	return strSql
+}
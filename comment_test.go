@@ -0,0 +1,71 @@
+package sqlca
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var regTraceId = regexp.MustCompile(`trace_id='([^']*)'`)
+
+func TestSqlCommentHostileValues(t *testing.T) {
+
+	e, done := newTestEngine(t)
+	defer done()
+
+	var strSql string
+	e.SetSqlComment(&SqlCommentOptions{App: "app*/", Tags: map[string]string{"k/*": "v',x=y"}})
+	e.Use(func(stmt *Statement, next Invoker) error {
+		strSql = stmt.SQL
+		return next(stmt)
+	})
+
+	var values = []string{
+		"**//; DROP TABLE users; -- ",
+		"*/; DROP TABLE users; /*",
+		"/*/",
+		"*'/",
+		"a'b,c=d\r\n--",
+		"trace id 100%",
+	}
+	for _, v := range values {
+		var n int64
+		ctx := ContextWithTraceId(context.Background(), v)
+		if _, err := e.Model(&n).Context(ctx).QueryRaw("SELECT 1"); err != nil || n != 1 {
+			t.Errorf("trace id %q: query [%v] returns %v error (%v)", v, strSql, n, err)
+			continue
+		}
+		//the only comment ends right before the statement
+		if strings.Count(strSql, "/*") != 1 || strings.Index(strSql, "*/") != len(strSql)-len("*/ SELECT 1") {
+			t.Errorf("trace id %q: comment closed early [%v]", v, strSql)
+			continue
+		}
+		m := regTraceId.FindStringSubmatch(strSql)
+		if m == nil {
+			t.Errorf("trace id %q: no trace_id in [%v]", v, strSql)
+			continue
+		}
+		if strValue, err := url.QueryUnescape(m[1]); err != nil || strValue != v {
+			t.Errorf("trace id %q: decoded %q error (%v)", v, strValue, err)
+		}
+	}
+}
+
+func TestFmtSqlCommentKV(t *testing.T) {
+
+	var cases = []struct {
+		strKey, strValue, strExpect string
+	}{
+		{"app", "user-service", "app='user-service'"},
+		{"file", "user.go:42", "file='user.go%3A42'"},
+		{"trace_id", "**//", "trace_id='%2A%2A%2F%2F'"},
+		{"k=,", "a b'", "k%3D%2C='a%20b%27'"},
+	}
+	for _, v := range cases {
+		if s := fmtSqlCommentKV(v.strKey, v.strValue); s != v.strExpect {
+			t.Errorf("%q=%q: %q expected, got %q", v.strKey, v.strValue, v.strExpect, s)
+		}
+	}
+}
@@ -2,7 +2,7 @@ package sqlca
 
 import (
 	"fmt"
-	"github.com/civet148/gotools/log"
+	"os"
 	"reflect"
 	"runtime"
 	"strings"
@@ -11,7 +11,7 @@ import (
 //assert bool and string/struct/slice/map nil, call panic
 func assert(v interface{}, strMsg string, args ...interface{}) {
 	if isNilOrFalse(v) {
-		log.Errorf(strMsg, args...)
+		defaultLogger.Error(fmt.Sprintf(strMsg, args...))
 	}
 }
 
@@ -58,6 +58,31 @@ func getCaller(skip int) (strFile, strFunc string, nLine int) {
 	return
 }
 
+// get the first caller outside sqlca package from call stack
+func getOuterCaller() (strFile, strFunc string, nLine int) {
+	strPkgPath := reflect.TypeOf(Engine{}).PkgPath() + "."
+	for skip := 1; ; skip++ {
+		pc, f, n, ok := runtime.Caller(skip)
+		if !ok {
+			return
+		}
+		if strings.HasPrefix(runtime.FuncForPC(pc).Name(), strPkgPath) {
+			continue
+		}
+		return f, getPkgFuncNameFromPC(pc), n
+	}
+}
+
+// get function name with package name (eg. 'handler.(*User).Get') from call stack
+func getPkgFuncNameFromPC(pc uintptr) (name string) {
+
+	name = runtime.FuncForPC(pc).Name()
+	if idx := strings.LastIndex(name, "/"); idx != -1 {
+		name = name[idx+1:]
+	}
+	return
+}
+
 // get function name from call stack
 func getFuncNameFromPC(pc uintptr) (name string) {
 
@@ -81,11 +106,12 @@ func (e *Engine) isCacheNil() bool {
 }
 
 func (e *Engine) setDebug(ok bool) {
-	log.SetLevel(log.LEVEL_DEBUG)
+	e.bDebug = ok
 }
 
 func (e *Engine) panic(strFmt string, args ...interface{}) {
 
 	//strFmt = fmtParentCaller(strFmt)
-	log.Fatalf(strFmt, args...)
+	e.errorf(strFmt, args...)
+	os.Exit(1)
 }
@@ -2,7 +2,7 @@ package sqlca
 
 import (
 	"database/sql/driver"
-	"github.com/civet148/gotools/log"
+	"fmt"
 	"github.com/shopspring/decimal"
 )
 
@@ -40,7 +40,7 @@ func NewDecimal(v interface{}) (d Decimal) {
 		d.dec = decimal.NewFromFloat(v.(float64))
 	case string:
 		if d.dec, err = decimal.NewFromString(v.(string)); err != nil {
-			log.Errorf(" decimal.NewFromString return error [%v]", err.Error())
+			defaultLogger.Error(fmt.Sprintf(" decimal.NewFromString return error [%v]", err.Error()))
 			panic(err.Error())
 		}
 	default:
@@ -0,0 +1,123 @@
+package sqlca
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// engine of a sqlite database in a temp dir, call done to close and remove it
+func newTestEngine(t *testing.T, strDDL ...string) (e *Engine, done func()) {
+
+	strDir, err := ioutil.TempDir("", "sqlca")
+	if err != nil {
+		t.Fatalf("make temp dir error (%v)", err)
+	}
+	e = NewEngine()
+	e.SetLogger(NewNopLogger())
+	if e.Open("sqlite3://"+filepath.Join(strDir, "test.db")) == nil {
+		os.RemoveAll(strDir)
+		t.Fatalf("open sqlite database in [%v] failed", strDir)
+	}
+	done = func() {
+		for _, db := range e.dbMasters {
+			db.Close()
+		}
+		os.RemoveAll(strDir)
+	}
+	for _, v := range strDDL {
+		if _, _, err = e.ExecRaw(v); err != nil {
+			done()
+			t.Fatalf("exec [%v] error (%v)", v, err)
+		}
+	}
+	return
+}
+
+type testUser struct {
+	Id   int64  `db:"id"`
+	Name string `db:"name"`
+	Age  int    `db:"age"`
+}
+
+// collapse spaces of generated sql
+func normalizeSql(strSql string) string {
+	return strings.Join(strings.Fields(strSql), " ")
+}
+
+func TestBuilderCopyOnWrite(t *testing.T) {
+
+	e, done := newTestEngine(t)
+	defer done()
+
+	var users []testUser
+	tpl := e.Model(&users).Table("users").Select("id", "name", "age").Where("age > 0") //columns of model are in map order
+	const strTpl = "SELECT id,name,age FROM users WHERE age > 0"
+
+	var cases = []struct {
+		e         *Engine
+		strExpect string
+	}{
+		{tpl.And("name = 'a'"), strTpl + " AND name = 'a'"},
+		{tpl.Desc("id").Limit(10), strTpl + " ORDER BY id DESC LIMIT 10"},
+		{tpl.Select("id").Id(1), "SELECT id FROM users WHERE `id`='1'"},
+		{tpl, strTpl},
+	}
+	for i, v := range cases {
+		if s := normalizeSql(v.e.ToSQL(OperType_Query)); s != v.strExpect {
+			t.Errorf("cases[%v]: %q expected, got %q", i, v.strExpect, s)
+		}
+	}
+
+	//builders derived from a shared template by goroutines
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			strExpect := fmt.Sprintf("%v AND id > %v", strTpl, n)
+			if s := normalizeSql(tpl.And("id > %v", n).ToSQL(OperType_Query)); s != strExpect {
+				t.Errorf("goroutine %v: %q expected, got %q", n, strExpect, s)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// engine settings change the engine they are called on, engines cloned before are not changed
+func TestEngineSetters(t *testing.T) {
+
+	e, done := newTestEngine(t)
+	defer done()
+
+	var users []testUser
+	before := e.Model(&users)
+	e.SetCustomTag("yaml")
+	e.SetPkName("uuid")
+	e.SetReadOnly("name")
+	if !inStrings(e.dbTags, "yaml") || e.GetPkName() != "uuid" || !inStrings(e.readOnly, "name") {
+		t.Fatalf("engine not changed, tags %v pk [%v] read only %v", e.dbTags, e.GetPkName(), e.readOnly)
+	}
+	if inStrings(before.dbTags, "yaml") || before.GetPkName() != DEFAULT_PRIMARY_KEY_NAME {
+		t.Errorf("engine cloned before changed, tags %v pk [%v]", before.dbTags, before.GetPkName())
+	}
+	after := e.Model(&users)
+	after.SetCustomTag("toml")
+	e.SetCustomTag("xml")
+	if inStrings(e.dbTags, "toml") || inStrings(after.dbTags, "xml") || !inStrings(after.dbTags, "yaml") {
+		t.Errorf("custom tags shared, engine %v clone %v", e.dbTags, after.dbTags)
+	}
+}
+
+func inStrings(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package sqlca
+
+import (
+	"reflect"
+)
+
+// model lifecycle hooks, implement on model pointer (eg. func (u *UserDO) BeforeInsert() error)
+// hooks are called for struct model and every element of slice model, a hook returns error aborts the operation
+// Upsert calls BeforeInsert/AfterInsert hooks
+
+type BeforeInsertHook interface {
+	BeforeInsert() error
+}
+
+type AfterInsertHook interface {
+	AfterInsert() error
+}
+
+type BeforeUpdateHook interface {
+	BeforeUpdate() error
+}
+
+type AfterUpdateHook interface {
+	AfterUpdate() error
+}
+
+type BeforeDeleteHook interface {
+	BeforeDelete() error
+}
+
+type AfterDeleteHook interface {
+	AfterDelete() error
+}
+
+type AfterQueryHook interface {
+	AfterQuery() error
+}
+
+func callBeforeInsert(v interface{}) (bool, error) {
+	if h, ok := v.(BeforeInsertHook); ok {
+		return true, h.BeforeInsert()
+	}
+	return false, nil
+}
+
+func callAfterInsert(v interface{}) (bool, error) {
+	if h, ok := v.(AfterInsertHook); ok {
+		return true, h.AfterInsert()
+	}
+	return false, nil
+}
+
+func callBeforeUpdate(v interface{}) (bool, error) {
+	if h, ok := v.(BeforeUpdateHook); ok {
+		return true, h.BeforeUpdate()
+	}
+	return false, nil
+}
+
+func callAfterUpdate(v interface{}) (bool, error) {
+	if h, ok := v.(AfterUpdateHook); ok {
+		return true, h.AfterUpdate()
+	}
+	return false, nil
+}
+
+func callBeforeDelete(v interface{}) (bool, error) {
+	if h, ok := v.(BeforeDeleteHook); ok {
+		return true, h.BeforeDelete()
+	}
+	return false, nil
+}
+
+func callAfterDelete(v interface{}) (bool, error) {
+	if h, ok := v.(AfterDeleteHook); ok {
+		return true, h.AfterDelete()
+	}
+	return false, nil
+}
+
+func callAfterQuery(v interface{}) (bool, error) {
+	if h, ok := v.(AfterQueryHook); ok {
+		return true, h.AfterQuery()
+	}
+	return false, nil
+}
+
+// call hook on struct model or every element of slice model
+// if hook returns error and engine is in a tx, the tx will be rolled back (eg. AfterQuery of TxGet)
+func (e *Engine) callHooks(fn func(v interface{}) (bool, error)) (called bool, err error) {
+
+	if e.model == nil {
+		return
+	}
+	val := reflect.ValueOf(e.model)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return
+	}
+	elem := val.Elem()
+	switch elem.Kind() {
+	case reflect.Struct:
+		called, err = fn(e.model)
+	case reflect.Slice:
+		for i := 0; i < elem.Len(); i++ {
+			v := elem.Index(i)
+			if v.Kind() != reflect.Ptr {
+				v = v.Addr()
+			} else if v.IsNil() {
+				continue
+			}
+			var ok bool
+			if ok, err = fn(v.Interface()); ok {
+				called = true
+			}
+			if err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		e.warnf("model hook aborted [%v] error [%v]", e.operType, err.Error())
+		e.autoRollback(true)
+	}
+	return
+}
+
+// call before hook, rebuild model dictionary if hook called (model may be changed by hook)
+func (e *Engine) callBeforeHooks(fn func(v interface{}) (bool, error)) (err error) {
+	var called bool
+	if called, err = e.callHooks(fn); err != nil {
+		return
+	}
+	if called {
+		e.dict = newReflector(e, e.model).ToMap(e.dbTags...)
+	}
+	return
+}
+
+func (e *Engine) callAfterHooks(fn func(v interface{}) (bool, error)) (err error) {
+	_, err = e.callHooks(fn)
+	return
+}
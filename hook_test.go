@@ -0,0 +1,115 @@
+package sqlca
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testUsersDDL = "CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL DEFAULT '', age INTEGER NOT NULL DEFAULT 0)"
+
+var errHookDenied = errors.New("denied by hook")
+
+type hookUser struct {
+	Id      int64  `db:"id"`
+	Name    string `db:"name"`
+	Age     int    `db:"age"`
+	queried int
+}
+
+func (u *hookUser) BeforeInsert() error {
+	if u.Name == "" {
+		return errHookDenied
+	}
+	u.Name = strings.ToUpper(u.Name)
+	return nil
+}
+
+func (u *hookUser) AfterQuery() error {
+	u.queried++
+	if u.Name == "DENIED" {
+		return errHookDenied
+	}
+	return nil
+}
+
+func countTestUsers(t *testing.T, e *Engine) (n int64) {
+	if _, err := e.Model(&n).QueryRaw("SELECT COUNT(*) FROM users"); err != nil {
+		t.Fatalf("count users error (%v)", err)
+	}
+	return
+}
+
+func TestHooksInsertAndQuery(t *testing.T) {
+
+	e, done := newTestEngine(t, testUsersDDL)
+	defer done()
+
+	//model changed by before hook is saved
+	user := hookUser{Name: "john", Age: 20}
+	if _, err := e.Model(&user).Table("users").Insert(); err != nil {
+		t.Fatalf("insert error (%v)", err)
+	}
+	//before hook error aborts the statement
+	if _, err := e.Model(&hookUser{}).Table("users").Insert(); err != errHookDenied {
+		t.Errorf("error [%v] expected, got [%v]", errHookDenied, err)
+	}
+	if n := countTestUsers(t, e); n != 1 {
+		t.Errorf("1 user expected, got %v", n)
+	}
+
+	//after hook called for every element of slice model
+	e.ExecRaw("INSERT INTO users(name, age) VALUES('mary', 18)")
+	var users []hookUser
+	if _, err := e.Model(&users).Table("users").Asc("id").Query(); err != nil {
+		t.Fatalf("query error (%v)", err)
+	}
+	if len(users) != 2 || users[0].Name != "JOHN" || users[0].queried != 1 || users[1].queried != 1 {
+		t.Errorf("users %+v", users)
+	}
+}
+
+func TestHooksTxRollback(t *testing.T) {
+
+	e, done := newTestEngine(t, testUsersDDL)
+	defer done()
+
+	tx, err := e.TxBegin()
+	if err != nil {
+		t.Fatalf("begin tx error (%v)", err)
+	}
+	if _, _, err = tx.TxExec("INSERT INTO users(name) VALUES('DENIED')"); err != nil {
+		t.Fatalf("tx exec error (%v)", err)
+	}
+	//AfterQuery of TxGet returns error, tx is rolled back without AutoRollback
+	var users []hookUser
+	if _, err = tx.TxGet(&users, "SELECT * FROM users"); err != errHookDenied {
+		t.Errorf("error [%v] expected, got [%v]", errHookDenied, err)
+	}
+	if len(users) != 1 || users[0].queried != 1 {
+		t.Errorf("AfterQuery not called, users %+v", users)
+	}
+	if err = tx.TxCommit(); err != sql.ErrTxDone {
+		t.Errorf("tx not rolled back, commit returns [%v]", err)
+	}
+	if n := countTestUsers(t, e); n != 0 {
+		t.Errorf("0 user expected, got %v", n)
+	}
+
+	//tx without hook error is committed
+	if tx, err = e.TxBegin(); err != nil {
+		t.Fatalf("begin tx error (%v)", err)
+	}
+	tx.TxExec("INSERT INTO users(name) VALUES('john')")
+	users = nil
+	if _, err = tx.TxGet(&users, "SELECT * FROM users"); err != nil || len(users) != 1 || users[0].queried != 1 {
+		t.Errorf("tx get users %+v error (%v)", users, err)
+	}
+	if err = tx.TxCommit(); err != nil {
+		t.Errorf("commit error (%v)", err)
+	}
+	if n := countTestUsers(t, e); n != 1 {
+		t.Errorf("1 user expected, got %v", n)
+	}
+}
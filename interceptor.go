@@ -0,0 +1,74 @@
+package sqlca
+
+import (
+	"context"
+	"time"
+)
+
+// statement context passed through the interceptor chain
+type Statement struct {
+	OperType     OperType        // operation type
+	TableName    string          // table name (empty for raw sql)
+	SQL          string          // final sql to execute, interceptors can change it before calling next
+	Args         []interface{}   // arguments of raw sql (already formatted into SQL)
+	Node         string          // database node to execute (master or slave)
+	Context      context.Context // request context set by Engine.Context (maybe nil)
+	Duration     time.Duration   // execute duration (valid after next returned)
+	RowsAffected int64           // rows affected or fetched (valid after next returned)
+	Error        error           // execute error (valid after next returned)
+}
+
+// invoker executes the statement, it's the next handler of interceptor chain
+type Invoker func(stmt *Statement) error
+
+// interceptor wraps every statement the engine runs
+// call next to continue the chain, return an error without calling next to abort the statement
+type Interceptor func(stmt *Statement, next Invoker) error
+
+// append interceptors to engine, the first one is the outermost
+// this function must calls before Model()
+func (e *Engine) Use(interceptors ...Interceptor) *Engine {
+	for _, v := range interceptors {
+		if v == nil {
+			continue
+		}
+		//engines cloned before (eg. by Model) share the slice, they must not see the new interceptor
+		e.interceptors = append(e.interceptors[:len(e.interceptors):len(e.interceptors)], v)
+	}
+	return e
+}
+
+// run statement through the interceptor chain, fn executes the final sql and returns rows affected
+func (e *Engine) intercept(strNode, strSql string, args []interface{}, fn func(strSql string) (int64, error)) (rowsAffected int64, err error) {
+
+	stmt := &Statement{
+		OperType:  e.getOperType(),
+		TableName: e.getTableName(),
+		SQL:       strSql,
+		Args:      args,
+		Node:      strNode,
+		Context:   e.ctx,
+	}
+
+	var invoker Invoker = func(stmt *Statement) error {
+		start := time.Now()
+		stmt.RowsAffected, stmt.Error = fn(stmt.SQL)
+		stmt.Duration = time.Since(start)
+		return stmt.Error
+	}
+
+	for i := len(e.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := e.interceptors[i], invoker
+		invoker = func(stmt *Statement) error {
+			return interceptor(stmt, next)
+		}
+	}
+
+	err = invoker(stmt)
+	if err != nil && stmt.Error == nil {
+		e.warnf("[%v] SQL [%v] aborted by interceptor [%v]", stmt.OperType, stmt.SQL, err.Error())
+	} else {
+		e.logStatement(stmt)
+	}
+	return stmt.RowsAffected, err
+}
@@ -0,0 +1,103 @@
+package sqlca
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// interceptor appends its name to calls before and after next
+func newTraceInterceptor(strName string, calls *[]string) Interceptor {
+	return func(stmt *Statement, next Invoker) error {
+		*calls = append(*calls, strName)
+		err := next(stmt)
+		*calls = append(*calls, strName+".done")
+		return err
+	}
+}
+
+func TestInterceptorOrder(t *testing.T) {
+
+	e, done := newTestEngine(t)
+	defer done()
+
+	var calls []string
+	var stmts []Statement
+	e.Use(newTraceInterceptor("a", &calls), nil, newTraceInterceptor("b", &calls))
+	e.Use(func(stmt *Statement, next Invoker) error {
+		err := next(stmt)
+		stmts = append(stmts, *stmt)
+		return err
+	})
+
+	var n int64
+	if _, err := e.Model(&n).QueryRaw("SELECT %v", 1); err != nil {
+		t.Fatalf("query error (%v)", err)
+	}
+	if expect := []string{"a", "b", "b.done", "a.done"}; !reflect.DeepEqual(calls, expect) {
+		t.Errorf("calls %v expected, got %v", expect, calls)
+	}
+	if len(stmts) != 1 || stmts[0].OperType != OperType_QueryRaw || stmts[0].SQL != "SELECT 1" ||
+		stmts[0].RowsAffected != 1 || stmts[0].Node != NODE_NAME_MASTER {
+		t.Errorf("statement %+v", stmts)
+	}
+}
+
+func TestInterceptorAbort(t *testing.T) {
+
+	e, done := newTestEngine(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, age INTEGER)")
+	defer done()
+
+	errDenied := errors.New("denied")
+	e.Use(func(stmt *Statement, next Invoker) error {
+		if stmt.OperType == OperType_ExecRaw {
+			return errDenied
+		}
+		return next(stmt)
+	})
+	if _, _, err := e.ExecRaw("DELETE FROM users"); err != errDenied {
+		t.Errorf("error [%v] expected, got [%v]", errDenied, err)
+	}
+	var n int64
+	if _, err := e.Model(&n).QueryRaw("SELECT COUNT(*) FROM users"); err != nil {
+		t.Errorf("query error (%v)", err)
+	}
+}
+
+// chains of engines cloned from the same engine diverge after Use
+func TestInterceptorChainCopy(t *testing.T) {
+
+	e, done := newTestEngine(t)
+	defer done()
+
+	var calls []string
+	for _, v := range []string{"1", "2", "3"} {
+		e.Use(newTraceInterceptor(v, &calls)) //append to a slice with spare capacity
+	}
+	var n int64
+	b := e.Model(&n)
+	b.Use(newTraceInterceptor("B", &calls))
+	e.Use(newTraceInterceptor("E", &calls))
+	e.EnableMetrics()
+	e.SetSlowThreshold(time.Second)
+	e.SetSqlComment(nil)
+	if len(b.interceptors) != 4 || len(e.interceptors) != 7 {
+		t.Fatalf("4 and 7 interceptors expected, got %v and %v", len(b.interceptors), len(e.interceptors))
+	}
+
+	calls = nil
+	if _, err := b.QueryRaw("SELECT 1"); err != nil {
+		t.Fatalf("query error (%v)", err)
+	}
+	if expect := []string{"1", "2", "3", "B", "B.done", "3.done", "2.done", "1.done"}; !reflect.DeepEqual(calls, expect) {
+		t.Errorf("calls of b %v expected, got %v", expect, calls)
+	}
+	calls = nil
+	if _, err := e.Model(&n).QueryRaw("SELECT 1"); err != nil {
+		t.Fatalf("query error (%v)", err)
+	}
+	if expect := []string{"1", "2", "3", "E", "E.done", "3.done", "2.done", "1.done"}; !reflect.DeepEqual(calls, expect) {
+		t.Errorf("calls of e %v expected, got %v", expect, calls)
+	}
+}
@@ -0,0 +1,333 @@
+package sqlca
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/civet148/gotools/log"
+	"io"
+	stdlog "log"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	LOG_FIELD_SQL      = "sql"
+	LOG_FIELD_ARGS     = "args"
+	LOG_FIELD_DURATION = "duration"
+	LOG_FIELD_TABLE    = "table"
+	LOG_FIELD_OPER     = "oper"
+	LOG_FIELD_ROWS     = "rows"
+	LOG_FIELD_NODE     = "node"
+	LOG_FIELD_ERROR    = "error"
+)
+
+type LogLevel int
+
+const (
+	LogLevel_Debug LogLevel = 0
+	LogLevel_Info  LogLevel = 1
+	LogLevel_Warn  LogLevel = 2
+	LogLevel_Error LogLevel = 3
+)
+
+func (l LogLevel) GoString() string {
+	return l.String()
+}
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevel_Debug:
+		return "DEBUG"
+	case LogLevel_Info:
+		return "INFO"
+	case LogLevel_Warn:
+		return "WARN"
+	case LogLevel_Error:
+		return "ERROR"
+	}
+	return "UNKNOWN"
+}
+
+// structured log field
+type LogField struct {
+	Key   string
+	Value interface{}
+}
+
+// engine logger, debug messages are only sent when engine debug mode on
+type Logger interface {
+	Debug(strMsg string, fields ...LogField)
+	Info(strMsg string, fields ...LogField)
+	Warn(strMsg string, fields ...LogField)
+	Error(strMsg string, fields ...LogField)
+}
+
+// logger for code paths without an engine (url/decimal parsing)
+var defaultLogger Logger = NewGoToolsLogger()
+
+// set default logger of new engines and code paths without an engine
+func SetDefaultLogger(logger Logger) {
+	if logger == nil {
+		logger = NewNopLogger()
+	}
+	defaultLogger = logger
+}
+
+// path of this file, frames of it are logger adapters and engine log helpers
+var strLoggerFile = func() string {
+	_, strFile, _, _ := runtime.Caller(0)
+	return strFile
+}()
+
+// first caller out of this file, skip is for runtime.Caller called by the function which calls getLogCaller
+func getLogCaller() (skip int, strFile, strFunc string, nLine int) {
+	for i := 2; ; i++ {
+		pc, f, n, ok := runtime.Caller(i)
+		if !ok {
+			return i - 1, "", "", 0
+		}
+		if f != strLoggerFile {
+			return i - 1, f, getFuncNameFromPC(pc), n
+		}
+	}
+}
+
+func fmtLogFields(fields ...LogField) string {
+	var ss []string
+	for _, v := range fields {
+		ss = append(ss, fmt.Sprintf("%v=[%v]", v.Key, v.Value))
+	}
+	return strings.Join(ss, " ")
+}
+
+func fmtLogMessage(strMsg string, fields ...LogField) string {
+	if len(fields) == 0 {
+		return strMsg
+	}
+	return strMsg + " " + fmtLogFields(fields...)
+}
+
+//------------------------------------------------------------------------------------------------------
+// gotools logger (default)
+
+type goToolsLogger struct {
+}
+
+// logger writes to gotools log (the default logger)
+func NewGoToolsLogger() Logger {
+	return &goToolsLogger{}
+}
+
+// gotools log prints the line of this file as caller, so the real caller is prepended like <sqlx.go:120 Query()>
+func (l *goToolsLogger) fmtMessage(strMsg string, fields ...LogField) string {
+	_, strFile, strFunc, nLine := getLogCaller()
+	if strFile == "" {
+		return fmtLogMessage(strMsg, fields...)
+	}
+	return fmt.Sprintf("<%v:%v %v()> %v", filepath.Base(strFile), nLine, strFunc, fmtLogMessage(strMsg, fields...))
+}
+
+func (l *goToolsLogger) Debug(strMsg string, fields ...LogField) {
+	log.Debugf("%s", l.fmtMessage(strMsg, fields...))
+}
+
+func (l *goToolsLogger) Info(strMsg string, fields ...LogField) {
+	log.Infof("%s", l.fmtMessage(strMsg, fields...))
+}
+
+func (l *goToolsLogger) Warn(strMsg string, fields ...LogField) {
+	log.Warnf("%s", l.fmtMessage(strMsg, fields...))
+}
+
+func (l *goToolsLogger) Error(strMsg string, fields ...LogField) {
+	log.Errorf("%s", l.fmtMessage(strMsg, fields...))
+}
+
+//------------------------------------------------------------------------------------------------------
+// standard library logger
+
+type stdLogger struct {
+	logger *stdlog.Logger
+}
+
+// logger writes to a standard library logger, nil means the standard logger
+func NewStdLogger(logger *stdlog.Logger) Logger {
+	if logger == nil {
+		logger = stdlog.New(stdlog.Writer(), stdlog.Prefix(), stdlog.Flags())
+	}
+	return &stdLogger{logger: logger}
+}
+
+// file:line of Lshortfile/Llongfile flags is the caller of engine log functions
+func (l *stdLogger) output(level LogLevel, strMsg string, fields ...LogField) {
+	skip, _, _, _ := getLogCaller()
+	_ = l.logger.Output(skip+1, fmt.Sprintf("[%v] %v", level, fmtLogMessage(strMsg, fields...)))
+}
+
+func (l *stdLogger) Debug(strMsg string, fields ...LogField) {
+	l.output(LogLevel_Debug, strMsg, fields...)
+}
+
+func (l *stdLogger) Info(strMsg string, fields ...LogField) {
+	l.output(LogLevel_Info, strMsg, fields...)
+}
+
+func (l *stdLogger) Warn(strMsg string, fields ...LogField) {
+	l.output(LogLevel_Warn, strMsg, fields...)
+}
+
+func (l *stdLogger) Error(strMsg string, fields ...LogField) {
+	l.output(LogLevel_Error, strMsg, fields...)
+}
+
+//------------------------------------------------------------------------------------------------------
+// structured json logger (one json object per line like log/slog JSONHandler)
+
+type jsonLogger struct {
+	locker sync.Mutex
+	writer io.Writer
+}
+
+// logger writes json lines: {"time":"...","level":"INFO","msg":"...","sql":"...",...}
+func NewJsonLogger(w io.Writer) Logger {
+	return &jsonLogger{writer: w}
+}
+
+func (l *jsonLogger) output(level LogLevel, strMsg string, fields ...LogField) {
+
+	var sb strings.Builder
+	sb.WriteString("{")
+	l.writeField(&sb, "time", time.Now().Format(time.RFC3339Nano))
+	sb.WriteString(",")
+	l.writeField(&sb, "level", level.String())
+	sb.WriteString(",")
+	l.writeField(&sb, "msg", strMsg)
+	for _, v := range fields {
+		sb.WriteString(",")
+		l.writeField(&sb, v.Key, v.Value)
+	}
+	sb.WriteString("}\n")
+
+	l.locker.Lock()
+	defer l.locker.Unlock()
+	_, _ = io.WriteString(l.writer, sb.String())
+}
+
+func (l *jsonLogger) writeField(sb *strings.Builder, strKey string, value interface{}) {
+	switch v := value.(type) {
+	case error:
+		value = v.Error()
+	case time.Duration:
+		value = v.String()
+	case fmt.Stringer:
+		value = v.String()
+	}
+	key, _ := json.Marshal(strKey)
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprintf("%v", value))
+	}
+	sb.Write(key)
+	sb.WriteString(":")
+	sb.Write(data)
+}
+
+func (l *jsonLogger) Debug(strMsg string, fields ...LogField) {
+	l.output(LogLevel_Debug, strMsg, fields...)
+}
+
+func (l *jsonLogger) Info(strMsg string, fields ...LogField) {
+	l.output(LogLevel_Info, strMsg, fields...)
+}
+
+func (l *jsonLogger) Warn(strMsg string, fields ...LogField) {
+	l.output(LogLevel_Warn, strMsg, fields...)
+}
+
+func (l *jsonLogger) Error(strMsg string, fields ...LogField) {
+	l.output(LogLevel_Error, strMsg, fields...)
+}
+
+//------------------------------------------------------------------------------------------------------
+// no-op logger
+
+type nopLogger struct {
+}
+
+// logger discards everything
+func NewNopLogger() Logger {
+	return &nopLogger{}
+}
+
+func (l *nopLogger) Debug(strMsg string, fields ...LogField) {}
+func (l *nopLogger) Info(strMsg string, fields ...LogField)  {}
+func (l *nopLogger) Warn(strMsg string, fields ...LogField)  {}
+func (l *nopLogger) Error(strMsg string, fields ...LogField) {}
+
+//------------------------------------------------------------------------------------------------------
+// engine log helpers
+
+// set engine logger, nil means no-op logger
+// this function must calls before Model()
+func (e *Engine) SetLogger(logger Logger) *Engine {
+	if logger == nil {
+		logger = NewNopLogger()
+	}
+	e.logger = logger
+	return e
+}
+
+// get engine logger
+func (e *Engine) GetLogger() Logger {
+	if e.logger == nil {
+		return defaultLogger
+	}
+	return e.logger
+}
+
+func (e *Engine) isDebug() bool {
+	return e.bDebug
+}
+
+func (e *Engine) debugf(strFmt string, args ...interface{}) {
+	if e.isDebug() {
+		e.GetLogger().Debug(fmt.Sprintf(strFmt, args...))
+	}
+}
+
+func (e *Engine) infof(strFmt string, args ...interface{}) {
+	e.GetLogger().Info(fmt.Sprintf(strFmt, args...))
+}
+
+func (e *Engine) warnf(strFmt string, args ...interface{}) {
+	e.GetLogger().Warn(fmt.Sprintf(strFmt, args...))
+}
+
+func (e *Engine) errorf(strFmt string, args ...interface{}) {
+	e.GetLogger().Error(fmt.Sprintf(strFmt, args...))
+}
+
+// log statement with structured fields: debug if succeed (debug mode on), error if failed
+func (e *Engine) logStatement(stmt *Statement) {
+
+	fields := []LogField{
+		{Key: LOG_FIELD_OPER, Value: stmt.OperType},
+		{Key: LOG_FIELD_TABLE, Value: stmt.TableName},
+		{Key: LOG_FIELD_SQL, Value: stmt.SQL},
+		{Key: LOG_FIELD_ARGS, Value: stmt.Args},
+		{Key: LOG_FIELD_DURATION, Value: stmt.Duration},
+		{Key: LOG_FIELD_ROWS, Value: stmt.RowsAffected},
+		{Key: LOG_FIELD_NODE, Value: stmt.Node},
+	}
+	if stmt.Error != nil {
+		fields = append(fields, LogField{Key: LOG_FIELD_ERROR, Value: stmt.Error})
+		e.GetLogger().Error("statement error", fields...)
+		return
+	}
+	if e.isDebug() {
+		e.GetLogger().Debug("statement", fields...)
+	}
+}
@@ -0,0 +1,89 @@
+package sqlca
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	stdlog "log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStdLoggerCaller(t *testing.T) {
+
+	e, done := newTestEngine(t)
+	defer done()
+
+	var buf bytes.Buffer
+	e.SetLogger(NewStdLogger(stdlog.New(&buf, "", stdlog.Lshortfile)))
+	e.warnf("hello")
+	if s := buf.String(); !strings.HasPrefix(s, "logger_test.go:") || !strings.Contains(s, "[WARN] hello") {
+		t.Errorf("caller of warnf expected, got %q", s)
+	}
+
+	buf.Reset()
+	var n int64
+	if _, err := e.Model(&n).QueryRaw("SELECT COUNT(*) FROM not_exist"); err == nil {
+		t.Fatalf("query of not existed table returns no error")
+	}
+	if s := buf.String(); !strings.HasPrefix(s, "interceptor.go:") || !strings.Contains(s, "statement error") {
+		t.Errorf("caller of statement log expected, got %q", s)
+	}
+}
+
+func TestGoToolsLoggerCaller(t *testing.T) {
+
+	e, done := newTestEngine(t)
+	defer done()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe error (%v)", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	e.SetLogger(NewGoToolsLogger())
+	e.warnf("hello")
+	os.Stdout = stdout
+	w.Close()
+	data, _ := ioutil.ReadAll(r)
+	if s := string(data); !strings.Contains(s, "<logger_test.go:") || !strings.Contains(s, "TestGoToolsLoggerCaller()> hello") {
+		t.Errorf("caller of warnf expected, got %q", s)
+	}
+}
+
+func TestJsonLoggerStatement(t *testing.T) {
+
+	e, done := newTestEngine(t)
+	defer done()
+
+	var buf bytes.Buffer
+	e.SetLogger(NewJsonLogger(&buf))
+	var n int64
+	e.Model(&n).QueryRaw("SELECT 1")
+	if buf.Len() != 0 {
+		t.Errorf("statement logged without debug mode: %v", buf.String())
+	}
+
+	e.Debug(true)
+	e.Model(&n).QueryRaw("SELECT %v", 2)
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("unmarshal %q error (%v)", buf.String(), err)
+	}
+	if m["level"] != "DEBUG" || m["msg"] != "statement" || m[LOG_FIELD_SQL] != "SELECT 2" ||
+		m[LOG_FIELD_OPER] != OperType_QueryRaw.String() || m[LOG_FIELD_ROWS] != float64(1) {
+		t.Errorf("statement log %v", m)
+	}
+
+	//debug mode of other engines is not changed
+	other, done2 := newTestEngine(t)
+	defer done2()
+	var buf2 bytes.Buffer
+	other.SetLogger(NewJsonLogger(&buf2))
+	other.Model(&n).QueryRaw("SELECT 1")
+	if buf2.Len() != 0 {
+		t.Errorf("statement of other engine logged: %v", buf2.String())
+	}
+}
@@ -0,0 +1,25 @@
+package sqlca
+
+// index descriptor of table (generated by db2go), eg. Cache(UsersIndexPhone.Columns...)
+type TableIndex struct {
+	Name     string   //index name (PRIMARY for primary key)
+	Columns  []string //columns order by sequence in index
+	IsUnique bool     //unique index
+}
+
+const (
+	RELATION_HAS_ONE    = "has-one"
+	RELATION_HAS_MANY   = "has-many"
+	RELATION_BELONGS_TO = "belongs-to"
+)
+
+// relationship of table by foreign key (generated by db2go), eg. classes.user_id -> users.id
+// Columns of Table reference (belongs-to) or are referenced by (has-one/has-many) RefColumns of RefTable
+type TableRelation struct {
+	Kind       string   //has-one, has-many or belongs-to
+	Name       string   //foreign key constraint name
+	Table      string   //table name
+	Columns    []string //columns of table
+	RefTable   string   //related table name
+	RefColumns []string //columns of related table
+}
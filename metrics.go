@@ -0,0 +1,284 @@
+package sqlca
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	METRICS_OPER_QUERY  = "query"
+	METRICS_OPER_INSERT = "insert"
+	METRICS_OPER_UPDATE = "update"
+	METRICS_OPER_UPSERT = "upsert"
+	METRICS_OPER_DELETE = "delete"
+	METRICS_OPER_RAW    = "raw"
+)
+
+// default latency histogram buckets (seconds)
+var DefaultLatencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type operKey struct {
+	Operation string
+	Table     string
+}
+
+type operCounter struct {
+	count   uint64
+	errors  uint64
+	sum     float64
+	buckets []uint64
+}
+
+// engine metrics: per operation/table counters and latency histograms, cache counters and pool stats
+type Metrics struct {
+	locker      sync.RWMutex
+	engine      *Engine
+	buckets     []float64
+	opers       map[operKey]*operCounter
+	cacheHits   uint64
+	cacheMisses uint64
+	cacheErrors uint64
+}
+
+// metrics of one operation on one table
+type OperMetric struct {
+	Operation string    // query/insert/update/upsert/delete/raw
+	Table     string    // table name
+	Count     uint64    // statements executed
+	Errors    uint64    // statements failed or aborted
+	Seconds   float64   // total latency of seconds
+	Buckets   []float64 // histogram upper bounds of seconds
+	Counts    []uint64  // cumulative count of each bucket
+}
+
+// cache metrics
+type CacheMetric struct {
+	Hits   uint64
+	Misses uint64
+	Errors uint64
+}
+
+// connection pool stats of a master or slave
+type PoolMetric struct {
+	Node  string // master or slave
+	Index int    // index of master or slave
+	Stats sql.DBStats
+}
+
+// metrics snapshot for other sinks
+type MetricsSnapshot struct {
+	Opers []OperMetric
+	Cache CacheMetric
+	Pools []PoolMetric
+}
+
+// enable built-in metrics, returns the metrics which can be exposed by Handler or read by Snapshot
+// this function must calls before Model()
+func (e *Engine) EnableMetrics(buckets ...float64) *Metrics {
+	if e.metrics != nil {
+		return e.metrics
+	}
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64(nil), buckets...) //never sort the caller's slice or DefaultLatencyBuckets
+	sort.Float64s(buckets)
+	e.metrics = &Metrics{
+		engine:  e,
+		buckets: buckets,
+		opers:   make(map[operKey]*operCounter),
+	}
+	e.Use(e.metrics.intercept)
+	return e.metrics
+}
+
+// get engine metrics, nil if metrics not enabled
+func (e *Engine) GetMetrics() *Metrics {
+	return e.metrics
+}
+
+func (e *Engine) incCacheHit() {
+	if e.metrics != nil {
+		e.metrics.locker.Lock()
+		e.metrics.cacheHits++
+		e.metrics.locker.Unlock()
+	}
+}
+
+func (e *Engine) incCacheMiss() {
+	if e.metrics != nil {
+		e.metrics.locker.Lock()
+		e.metrics.cacheMisses++
+		e.metrics.locker.Unlock()
+	}
+}
+
+func (e *Engine) incCacheError() {
+	if e.metrics != nil {
+		e.metrics.locker.Lock()
+		e.metrics.cacheErrors++
+		e.metrics.locker.Unlock()
+	}
+}
+
+func getMetricsOperation(operType OperType) string {
+	switch operType {
+	case OperType_Query, OperType_ForUpdate:
+		return METRICS_OPER_QUERY
+	case OperType_Insert:
+		return METRICS_OPER_INSERT
+	case OperType_Update:
+		return METRICS_OPER_UPDATE
+	case OperType_Upsert:
+		return METRICS_OPER_UPSERT
+	case OperType_Delete:
+		return METRICS_OPER_DELETE
+	}
+	return METRICS_OPER_RAW
+}
+
+func (m *Metrics) intercept(stmt *Statement, next Invoker) error {
+	err := next(stmt)
+	m.observe(getMetricsOperation(stmt.OperType), stmt.TableName, stmt.Duration, err)
+	return err
+}
+
+func (m *Metrics) observe(strOper, strTable string, duration time.Duration, err error) {
+
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	key := operKey{Operation: strOper, Table: strTable}
+	c, ok := m.opers[key]
+	if !ok {
+		c = &operCounter{buckets: make([]uint64, len(m.buckets))}
+		m.opers[key] = c
+	}
+	seconds := duration.Seconds()
+	c.count++
+	c.sum += seconds
+	if err != nil {
+		c.errors++
+	}
+	for i, v := range m.buckets {
+		if seconds <= v {
+			c.buckets[i]++
+		}
+	}
+}
+
+// take a snapshot of all metrics
+func (m *Metrics) Snapshot() (snapshot *MetricsSnapshot) {
+
+	snapshot = &MetricsSnapshot{}
+
+	m.locker.RLock()
+	for k, v := range m.opers {
+		snapshot.Opers = append(snapshot.Opers, OperMetric{
+			Operation: k.Operation,
+			Table:     k.Table,
+			Count:     v.count,
+			Errors:    v.errors,
+			Seconds:   v.sum,
+			Buckets:   m.buckets,
+			Counts:    append([]uint64(nil), v.buckets...),
+		})
+	}
+	snapshot.Cache = CacheMetric{
+		Hits:   m.cacheHits,
+		Misses: m.cacheMisses,
+		Errors: m.cacheErrors,
+	}
+	m.locker.RUnlock()
+
+	sort.Slice(snapshot.Opers, func(i, j int) bool {
+		if snapshot.Opers[i].Operation != snapshot.Opers[j].Operation {
+			return snapshot.Opers[i].Operation < snapshot.Opers[j].Operation
+		}
+		return snapshot.Opers[i].Table < snapshot.Opers[j].Table
+	})
+
+	for i, db := range m.engine.dbMasters {
+		snapshot.Pools = append(snapshot.Pools, PoolMetric{Node: NODE_NAME_MASTER, Index: i, Stats: db.Stats()})
+	}
+	for i, db := range m.engine.dbSlaves {
+		snapshot.Pools = append(snapshot.Pools, PoolMetric{Node: NODE_NAME_SLAVE, Index: i, Stats: db.Stats()})
+	}
+	return
+}
+
+// http handler of prometheus text exposition format
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = m.WritePrometheus(w)
+	})
+}
+
+// write all metrics to w in prometheus text exposition format
+func (m *Metrics) WritePrometheus(w io.Writer) (err error) {
+
+	var sb strings.Builder
+	snapshot := m.Snapshot()
+
+	sb.WriteString("# HELP sqlca_statements_total Statements executed by operation and table.\n")
+	sb.WriteString("# TYPE sqlca_statements_total counter\n")
+	for _, v := range snapshot.Opers {
+		sb.WriteString(fmt.Sprintf("sqlca_statements_total{operation=%q,table=%q} %v\n", v.Operation, v.Table, v.Count))
+	}
+
+	sb.WriteString("# HELP sqlca_statement_errors_total Statements failed or aborted by operation and table.\n")
+	sb.WriteString("# TYPE sqlca_statement_errors_total counter\n")
+	for _, v := range snapshot.Opers {
+		sb.WriteString(fmt.Sprintf("sqlca_statement_errors_total{operation=%q,table=%q} %v\n", v.Operation, v.Table, v.Errors))
+	}
+
+	sb.WriteString("# HELP sqlca_statement_duration_seconds Statement latency by operation and table.\n")
+	sb.WriteString("# TYPE sqlca_statement_duration_seconds histogram\n")
+	for _, v := range snapshot.Opers {
+		for i, le := range v.Buckets {
+			sb.WriteString(fmt.Sprintf("sqlca_statement_duration_seconds_bucket{operation=%q,table=%q,le=\"%v\"} %v\n", v.Operation, v.Table, le, v.Counts[i]))
+		}
+		sb.WriteString(fmt.Sprintf("sqlca_statement_duration_seconds_bucket{operation=%q,table=%q,le=\"+Inf\"} %v\n", v.Operation, v.Table, v.Count))
+		sb.WriteString(fmt.Sprintf("sqlca_statement_duration_seconds_sum{operation=%q,table=%q} %v\n", v.Operation, v.Table, v.Seconds))
+		sb.WriteString(fmt.Sprintf("sqlca_statement_duration_seconds_count{operation=%q,table=%q} %v\n", v.Operation, v.Table, v.Count))
+	}
+
+	sb.WriteString("# HELP sqlca_cache_requests_total Cache requests by result.\n")
+	sb.WriteString("# TYPE sqlca_cache_requests_total counter\n")
+	sb.WriteString(fmt.Sprintf("sqlca_cache_requests_total{result=\"hit\"} %v\n", snapshot.Cache.Hits))
+	sb.WriteString(fmt.Sprintf("sqlca_cache_requests_total{result=\"miss\"} %v\n", snapshot.Cache.Misses))
+	sb.WriteString(fmt.Sprintf("sqlca_cache_requests_total{result=\"error\"} %v\n", snapshot.Cache.Errors))
+
+	var gauges = []struct {
+		name  string
+		help  string
+		typ   string
+		value func(s sql.DBStats) interface{}
+	}{
+		{"sqlca_pool_max_open_connections", "Maximum number of open connections.", "gauge", func(s sql.DBStats) interface{} { return s.MaxOpenConnections }},
+		{"sqlca_pool_open_connections", "Number of established connections both in use and idle.", "gauge", func(s sql.DBStats) interface{} { return s.OpenConnections }},
+		{"sqlca_pool_in_use_connections", "Number of connections currently in use.", "gauge", func(s sql.DBStats) interface{} { return s.InUse }},
+		{"sqlca_pool_idle_connections", "Number of idle connections.", "gauge", func(s sql.DBStats) interface{} { return s.Idle }},
+		{"sqlca_pool_wait_count_total", "Total number of connections waited for.", "counter", func(s sql.DBStats) interface{} { return s.WaitCount }},
+		{"sqlca_pool_wait_duration_seconds_total", "Total time blocked waiting for a new connection.", "counter", func(s sql.DBStats) interface{} { return s.WaitDuration.Seconds() }},
+		{"sqlca_pool_max_idle_closed_total", "Total number of connections closed due to SetMaxIdleConns.", "counter", func(s sql.DBStats) interface{} { return s.MaxIdleClosed }},
+		{"sqlca_pool_max_lifetime_closed_total", "Total number of connections closed due to SetConnMaxLifetime.", "counter", func(s sql.DBStats) interface{} { return s.MaxLifetimeClosed }},
+	}
+	for _, g := range gauges {
+		sb.WriteString(fmt.Sprintf("# HELP %v %v\n", g.name, g.help))
+		sb.WriteString(fmt.Sprintf("# TYPE %v %v\n", g.name, g.typ))
+		for _, p := range snapshot.Pools {
+			sb.WriteString(fmt.Sprintf("%v{node=%q,index=\"%v\"} %v\n", g.name, p.Node, p.Index, g.value(p.Stats)))
+		}
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return
+}
@@ -0,0 +1,83 @@
+package sqlca
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMetricsBuckets(t *testing.T) {
+
+	e, done := newTestEngine(t)
+	defer done()
+
+	buckets := []float64{1, 0.1, 0.5}
+	m := e.EnableMetrics(buckets...)
+	if !reflect.DeepEqual(buckets, []float64{1, 0.1, 0.5}) {
+		t.Errorf("caller's buckets must not be sorted, got %v", buckets)
+	}
+	if !reflect.DeepEqual(m.buckets, []float64{0.1, 0.5, 1}) {
+		t.Errorf("sorted buckets expected, got %v", m.buckets)
+	}
+	if e.EnableMetrics() != m {
+		t.Errorf("metrics must be enabled once")
+	}
+
+	defaultBuckets := append([]float64(nil), DefaultLatencyBuckets...)
+	e2, done2 := newTestEngine(t)
+	defer done2()
+	if m2 := e2.EnableMetrics(); !reflect.DeepEqual(m2.buckets, defaultBuckets) || !reflect.DeepEqual(DefaultLatencyBuckets, defaultBuckets) {
+		t.Errorf("default buckets expected, got %v", m2.buckets)
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+
+	e, done := newTestEngine(t, testUsersDDL)
+	defer done()
+
+	m := e.EnableMetrics(10)
+	user := testUser{Name: "a", Age: 1}
+	if _, err := e.Model(&user).Table("users").Insert(); err != nil {
+		t.Fatalf("insert error (%v)", err)
+	}
+	var users []testUser
+	for i := 0; i < 2; i++ {
+		if _, err := e.Model(&users).Table("users").Query(); err != nil {
+			t.Fatalf("query error (%v)", err)
+		}
+	}
+	if _, err := e.Model(&users).Table("no_such_table").Query(); err == nil {
+		t.Fatalf("query of no such table must fail")
+	}
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if strType := w.Header().Get("Content-Type"); !strings.HasPrefix(strType, "text/plain; version=0.0.4") {
+		t.Errorf("prometheus content type expected, got %v", strType)
+	}
+	data, _ := ioutil.ReadAll(w.Body)
+	strBody := string(data)
+	for _, v := range []string{
+		`sqlca_statements_total{operation="insert",table="users"} 1`,
+		`sqlca_statements_total{operation="query",table="users"} 2`,
+		`sqlca_statement_errors_total{operation="query",table="users"} 0`,
+		`sqlca_statement_errors_total{operation="query",table="no_such_table"} 1`,
+		`sqlca_statement_duration_seconds_bucket{operation="query",table="users",le="10"} 2`,
+		`sqlca_statement_duration_seconds_bucket{operation="query",table="users",le="+Inf"} 2`,
+		`sqlca_statement_duration_seconds_count{operation="query",table="users"} 2`,
+		`sqlca_cache_requests_total{result="hit"} 0`,
+		`sqlca_pool_open_connections{node="master",index="0"}`,
+	} {
+		if !strings.Contains(strBody, v+"\n") && !strings.Contains(strBody, v+" ") {
+			t.Errorf("line [%v] expected in\n%v", v, strBody)
+		}
+	}
+
+	snapshot := m.Snapshot()
+	if len(snapshot.Opers) != 3 || snapshot.Opers[0].Operation != METRICS_OPER_INSERT {
+		t.Errorf("3 sorted operations expected, got %+v", snapshot.Opers)
+	}
+}
@@ -0,0 +1,109 @@
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/civet148/sqlca"
+)
+
+func (m *Migrator) quote(strName string) string {
+	switch m.engine.GetAdapter() {
+	case sqlca.AdapterSqlx_MySQL:
+		return "`" + strName + "`"
+	case sqlca.AdapterSqlx_Mssql:
+		return "[" + strName + "]"
+	}
+	return `"` + strName + `"`
+}
+
+// mysql commits DDL implicitly
+func (m *Migrator) isTransactionalDDL() bool {
+	return m.engine.GetAdapter() != sqlca.AdapterSqlx_MySQL
+}
+
+func (m *Migrator) lockTable() string {
+	return m.options.Table + "_lock"
+}
+
+func (m *Migrator) createTables() (err error) {
+
+	var strVarchar, strSmallInt = "VARCHAR", "SMALLINT"
+	if m.engine.GetAdapter() == sqlca.AdapterSqlx_Mssql {
+		strVarchar = "NVARCHAR"
+	}
+	tables := map[string]string{
+		m.options.Table: fmt.Sprintf("%v BIGINT NOT NULL PRIMARY KEY, %v %v(255) NOT NULL DEFAULT '', %v %v(64) NOT NULL DEFAULT '', %v %v NOT NULL DEFAULT 0, %v %v(32) NOT NULL DEFAULT ''",
+			m.quote("version"), m.quote("name"), strVarchar, m.quote("checksum"), strVarchar, m.quote("dirty"), strSmallInt, m.quote("applied_at"), strVarchar),
+		m.lockTable(): fmt.Sprintf("%v INT NOT NULL PRIMARY KEY, %v %v(255) NOT NULL DEFAULT '', %v BIGINT NOT NULL DEFAULT 0",
+			m.quote("id"), m.quote("owner"), strVarchar, m.quote("locked_at")),
+	}
+	for _, strName := range []string{m.options.Table, m.lockTable()} {
+		var strDDL string
+		switch m.engine.GetAdapter() {
+		case sqlca.AdapterSqlx_Mssql:
+			strDDL = fmt.Sprintf("IF OBJECT_ID(N'%v', N'U') IS NULL CREATE TABLE %v (%v)", strName, m.quote(strName), tables[strName])
+		default:
+			strDDL = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v (%v)", m.quote(strName), tables[strName])
+		}
+		if _, _, err = m.engine.ExecRaw(strDDL); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// lock by inserting the only row of lock table, the row expires after LockTTL if the owner crashed (not renewed)
+func (m *Migrator) lock() (err error) {
+
+	strTable := m.quote(m.lockTable())
+	strHost, _ := os.Hostname()
+	m.strOwner = escapeQuote(fmt.Sprintf("%v:%v:%v", strHost, os.Getpid(), time.Now().UnixNano()))
+	deadline := time.Now().Add(m.options.LockTimeout)
+	for {
+		now := time.Now().Unix()
+		_, _, _ = m.engine.ExecRaw(fmt.Sprintf("DELETE FROM %v WHERE %v=1 AND %v<%v",
+			strTable, m.quote("id"), m.quote("locked_at"), now-int64(m.options.LockTTL/time.Second)))
+
+		var count int64
+		if _, err = m.engine.Model(&count).QueryRaw(fmt.Sprintf("SELECT COUNT(*) FROM %v WHERE %v=1", strTable, m.quote("id"))); err != nil {
+			return
+		}
+		if count == 0 {
+			_, _, err = m.engine.ExecRaw(escapePercent(fmt.Sprintf("INSERT INTO %v (%v,%v,%v) VALUES (1,'%v',%v)",
+				strTable, m.quote("id"), m.quote("owner"), m.quote("locked_at"), m.strOwner, now)))
+			if err == nil {
+				return //primary key conflict if another instance locked first
+			}
+		}
+		if time.Now().After(deadline) {
+			return ErrLocked
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// refresh locked_at of the lock row, so the lock expires only if the owner crashed
+func (m *Migrator) renewLock() (err error) {
+
+	strTable := m.quote(m.lockTable())
+	strWhere := escapePercent(fmt.Sprintf("%v=1 AND %v='%v'", m.quote("id"), m.quote("owner"), m.strOwner))
+	if _, _, err = m.engine.ExecRaw(fmt.Sprintf("UPDATE %v SET %v=%v WHERE %v", strTable, m.quote("locked_at"), time.Now().Unix(), strWhere)); err != nil {
+		return
+	}
+	//mysql returns 0 rows affected if locked_at not changed, so check owner by query
+	var count int64
+	if _, err = m.engine.Model(&count).QueryRaw(fmt.Sprintf("SELECT COUNT(*) FROM %v WHERE %v", strTable, strWhere)); err != nil {
+		return
+	}
+	if count == 0 {
+		return ErrLockLost
+	}
+	return
+}
+
+func (m *Migrator) unlock() {
+	_, _, _ = m.engine.ExecRaw(escapePercent(fmt.Sprintf("DELETE FROM %v WHERE %v=1 AND %v='%v'",
+		m.quote(m.lockTable()), m.quote("id"), m.quote("owner"), m.strOwner)))
+}
@@ -0,0 +1,381 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/civet148/sqlca"
+)
+
+const (
+	DEFAULT_MIGRATIONS_TABLE = "schema_migrations"
+	DEFAULT_MIGRATIONS_DIR   = "migrations"
+	DEFAULT_LOCK_TIMEOUT     = 30 * time.Second
+	DEFAULT_LOCK_TTL         = 10 * time.Minute
+)
+
+var (
+	ErrDirty            = errors.New("database is dirty, fix the failed migration manually and call Force")
+	ErrLocked           = errors.New("migrations are locked by another instance")
+	ErrChecksumMismatch = errors.New("checksum of applied migration changed")
+	ErrNoChange         = errors.New("no migration to apply")
+	ErrLockLost         = errors.New("migration lock expired and taken by another instance")
+)
+
+// migration of one version, SQL or go functions (go functions first)
+// go functions are called with a tx engine, use TxExec/TxGet to execute statements
+type Migration struct {
+	Version  int64                        // version (eg. 20200316103000)
+	Name     string                       // name (eg. create_users)
+	UpSQL    string                       // up statements split by ';'
+	DownSQL  string                       // down statements split by ';'
+	Up       func(tx *sqlca.Engine) error // go function to migrate up
+	Down     func(tx *sqlca.Engine) error // go function to migrate down
+	Checksum string                       // sha256 of up and down SQL, empty for go functions
+}
+
+// migration status of local and applied
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool   // applied to database
+	AppliedAt string // datetime of applied
+	Dirty     bool   // failed when applying, need fix manually
+	Modified  bool   // checksum of local migration not equal to applied
+	Missing   bool   // applied but local migration not found
+}
+
+type Options struct {
+	Dir         string        // migration files directory, default 'migrations'
+	Table       string        // migrations table name, default 'schema_migrations' (lock table is '<table>_lock')
+	LockTimeout time.Duration // wait for lock released by another instance, default 30s
+	LockTTL     time.Duration // lock expired if the instance crashed, renewed before each migration (a single migration must finish in it), default 10m
+}
+
+type record struct {
+	Version   int64  `db:"version"`
+	Name      string `db:"name"`
+	Checksum  string `db:"checksum"`
+	Dirty     int    `db:"dirty"`
+	AppliedAt string `db:"applied_at"`
+}
+
+type Migrator struct {
+	engine     *sqlca.Engine
+	options    Options
+	migrations map[int64]*Migration
+	strOwner   string // lock owner (host:pid:nano)
+}
+
+func NewMigrator(e *sqlca.Engine, options ...*Options) *Migrator {
+
+	var opts Options
+	if len(options) > 0 && options[0] != nil {
+		opts = *options[0]
+	}
+	if opts.Dir == "" {
+		opts.Dir = DEFAULT_MIGRATIONS_DIR
+	}
+	if opts.Table == "" {
+		opts.Table = DEFAULT_MIGRATIONS_TABLE
+	}
+	if opts.LockTimeout <= 0 {
+		opts.LockTimeout = DEFAULT_LOCK_TIMEOUT
+	}
+	if opts.LockTTL <= 0 {
+		opts.LockTTL = DEFAULT_LOCK_TTL
+	}
+	return &Migrator{
+		engine:     e,
+		options:    opts,
+		migrations: make(map[int64]*Migration),
+	}
+}
+
+// register migrations (go functions or SQL)
+func (m *Migrator) Register(migrations ...*Migration) error {
+	for _, v := range migrations {
+		if v.Version <= 0 {
+			return fmt.Errorf("migration [%v] version must be positive", v.Name)
+		}
+		if _, ok := m.migrations[v.Version]; ok {
+			return fmt.Errorf("migration version [%v] duplicated", v.Version)
+		}
+		if v.Checksum == "" && (v.UpSQL != "" || v.DownSQL != "") {
+			v.Checksum = makeChecksum(v.UpSQL, v.DownSQL)
+		}
+		m.migrations[v.Version] = v
+	}
+	return nil
+}
+
+// load migration files from options directory
+func (m *Migrator) Load() error {
+	migrations, err := LoadDir(m.options.Dir)
+	if err != nil {
+		return err
+	}
+	return m.Register(migrations...)
+}
+
+// apply pending migrations, steps <= 0 means all
+func (m *Migrator) Up(steps ...int) (err error) {
+
+	if err = m.prepare(); err != nil {
+		return
+	}
+	defer m.unlock()
+
+	var applied map[int64]*record
+	if applied, err = m.checkApplied(); err != nil {
+		return
+	}
+	var pending []*Migration
+	for _, v := range m.sorted() {
+		if _, ok := applied[v.Version]; !ok {
+			pending = append(pending, v)
+		}
+	}
+	if len(steps) > 0 && steps[0] > 0 && steps[0] < len(pending) {
+		pending = pending[:steps[0]]
+	}
+	if len(pending) == 0 {
+		return ErrNoChange
+	}
+	for _, v := range pending {
+		if err = m.renewLock(); err != nil {
+			return
+		}
+		if err = m.apply(v, true); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// revert applied migrations from the latest, default 1 step, steps <= 0 means all
+func (m *Migrator) Down(steps ...int) (err error) {
+
+	if err = m.prepare(); err != nil {
+		return
+	}
+	defer m.unlock()
+
+	var applied map[int64]*record
+	if applied, err = m.checkApplied(); err != nil {
+		return
+	}
+	var versions []int64
+	for k := range applied {
+		versions = append(versions, k)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+	n := 1
+	if len(steps) > 0 {
+		n = steps[0]
+	}
+	if n > 0 && n < len(versions) {
+		versions = versions[:n]
+	}
+	if len(versions) == 0 {
+		return ErrNoChange
+	}
+	for _, v := range versions {
+		migration, ok := m.migrations[v]
+		if !ok {
+			return fmt.Errorf("migration version [%v] applied but not found in local", v)
+		}
+		if err = m.renewLock(); err != nil {
+			return
+		}
+		if err = m.apply(migration, false); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// status of local and applied migrations order by version
+func (m *Migrator) Status() (status []*MigrationStatus, err error) {
+
+	if err = m.createTables(); err != nil {
+		return
+	}
+	var applied map[int64]*record
+	if applied, err = m.queryApplied(); err != nil {
+		return
+	}
+	for _, v := range m.sorted() {
+		s := &MigrationStatus{Version: v.Version, Name: v.Name}
+		if r, ok := applied[v.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = r.AppliedAt
+			s.Dirty = r.Dirty != 0
+			s.Modified = v.Checksum != "" && r.Checksum != "" && v.Checksum != r.Checksum
+		}
+		status = append(status, s)
+	}
+	for _, r := range applied {
+		if _, ok := m.migrations[r.Version]; !ok {
+			status = append(status, &MigrationStatus{Version: r.Version, Name: r.Name, Applied: true, AppliedAt: r.AppliedAt, Dirty: r.Dirty != 0, Missing: true})
+		}
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Version < status[j].Version })
+	return
+}
+
+// clear dirty state of version after the failed migration fixed manually
+func (m *Migrator) Force(version int64) (err error) {
+
+	if err = m.prepare(); err != nil {
+		return
+	}
+	defer m.unlock()
+
+	var applied map[int64]*record
+	if applied, err = m.queryApplied(); err != nil {
+		return
+	}
+	if _, ok := applied[version]; !ok {
+		return fmt.Errorf("migration version [%v] not applied", version)
+	}
+	_, _, err = m.engine.ExecRaw(escapePercent(fmt.Sprintf("UPDATE %v SET %v=0 WHERE %v=%v",
+		m.quote(m.options.Table), m.quote("dirty"), m.quote("version"), version)))
+	return
+}
+
+func (m *Migrator) prepare() (err error) {
+	if err = m.createTables(); err != nil {
+		return
+	}
+	return m.lock()
+}
+
+func (m *Migrator) sorted() (migrations []*Migration) {
+	for _, v := range m.migrations {
+		migrations = append(migrations, v)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return
+}
+
+// applied records, refuse to migrate if dirty or checksum changed
+func (m *Migrator) checkApplied() (applied map[int64]*record, err error) {
+
+	if applied, err = m.queryApplied(); err != nil {
+		return
+	}
+	for _, r := range applied {
+		if r.Dirty != 0 {
+			return nil, fmt.Errorf("version [%v] %w", r.Version, ErrDirty)
+		}
+		if v, ok := m.migrations[r.Version]; ok && v.Checksum != "" && r.Checksum != "" && v.Checksum != r.Checksum {
+			return nil, fmt.Errorf("version [%v] name [%v] %w", r.Version, r.Name, ErrChecksumMismatch)
+		}
+	}
+	return
+}
+
+func (m *Migrator) queryApplied() (applied map[int64]*record, err error) {
+
+	var records []*record
+	_, err = m.engine.Model(&records).QueryRaw(escapePercent(fmt.Sprintf("SELECT %v,%v,%v,%v,%v FROM %v ORDER BY %v",
+		m.quote("version"), m.quote("name"), m.quote("checksum"), m.quote("dirty"), m.quote("applied_at"),
+		m.quote(m.options.Table), m.quote("version"))))
+	if err != nil {
+		return
+	}
+	applied = make(map[int64]*record)
+	for _, v := range records {
+		applied[v.Version] = v
+	}
+	return
+}
+
+// apply a migration up or down
+// the version is marked dirty before migration and cleaned after succeed
+func (m *Migrator) apply(v *Migration, up bool) (err error) {
+
+	e := m.engine
+	strTable := m.quote(m.options.Table)
+	if up {
+		_, _, err = e.ExecRaw(escapePercent(fmt.Sprintf("INSERT INTO %v (%v,%v,%v,%v,%v) VALUES (%v,'%v','%v',1,'')",
+			strTable, m.quote("version"), m.quote("name"), m.quote("checksum"), m.quote("dirty"), m.quote("applied_at"),
+			v.Version, escapeQuote(v.Name), v.Checksum)))
+	} else {
+		_, _, err = e.ExecRaw(fmt.Sprintf("UPDATE %v SET %v=1 WHERE %v=%v", strTable, m.quote("dirty"), m.quote("version"), v.Version))
+	}
+	if err != nil {
+		return
+	}
+
+	if err = m.run(v, up); err != nil {
+		return fmt.Errorf("migrate version [%v] name [%v] error [%w]", v.Version, v.Name, err)
+	}
+
+	if up {
+		_, _, err = e.ExecRaw(escapePercent(fmt.Sprintf("UPDATE %v SET %v=0,%v='%v' WHERE %v=%v",
+			strTable, m.quote("dirty"), m.quote("applied_at"), time.Now().Format(sqlca.DATETIME_FORMAT), m.quote("version"), v.Version)))
+	} else {
+		_, _, err = e.ExecRaw(fmt.Sprintf("DELETE FROM %v WHERE %v=%v", strTable, m.quote("version"), v.Version))
+	}
+	return
+}
+
+// run migration in a tx, if the database supports transactional DDL and rolled back, the dirty mark is restored
+func (m *Migrator) run(v *Migration, up bool) (err error) {
+
+	var tx *sqlca.Engine
+	if tx, err = m.engine.TxBegin(); err != nil {
+		return
+	}
+	fn, strSQL := v.Up, v.UpSQL
+	if !up {
+		fn, strSQL = v.Down, v.DownSQL
+	}
+	if fn != nil {
+		err = fn(tx)
+	} else {
+		for _, s := range SplitStatements(strSQL) {
+			if _, _, err = tx.TxExec(escapePercent(s)); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		if errRollback := tx.TxRollback(); errRollback == nil && m.isTransactionalDDL() {
+			m.restoreDirty(v, up)
+		}
+		return
+	}
+	return tx.TxCommit()
+}
+
+func (m *Migrator) restoreDirty(v *Migration, up bool) {
+	strTable := m.quote(m.options.Table)
+	if up {
+		_, _, _ = m.engine.ExecRaw(fmt.Sprintf("DELETE FROM %v WHERE %v=%v", strTable, m.quote("version"), v.Version))
+	} else {
+		_, _, _ = m.engine.ExecRaw(fmt.Sprintf("UPDATE %v SET %v=0 WHERE %v=%v", strTable, m.quote("dirty"), m.quote("version"), v.Version))
+	}
+}
+
+func makeChecksum(strUp, strDown string) string {
+	h := sha256.Sum256([]byte(strUp + "\n-- down --\n" + strDown))
+	return hex.EncodeToString(h[:])
+}
+
+// engine formats raw SQL by fmt.Sprintf
+func escapePercent(strSQL string) string {
+	return strings.Replace(strSQL, "%", "%%", -1)
+}
+
+func escapeQuote(strValue string) string {
+	return strings.Replace(strValue, "'", "''", -1)
+}
@@ -0,0 +1,261 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/civet148/sqlca"
+)
+
+// migrator of a sqlite database in a temp dir, call done to remove it
+func newTestMigrator(t *testing.T, migrations ...*Migration) (m *Migrator, done func()) {
+
+	strDir, err := ioutil.TempDir("", "migrate")
+	if err != nil {
+		t.Fatalf("make temp dir error (%v)", err)
+	}
+	e := sqlca.NewEngine()
+	e.SetLogger(sqlca.NewNopLogger())
+	if e.Open("sqlite3://"+filepath.Join(strDir, "test.db")) == nil {
+		os.RemoveAll(strDir)
+		t.Fatalf("open sqlite database in [%v] failed", strDir)
+	}
+	m = NewMigrator(e, &Options{Dir: strDir, LockTimeout: time.Millisecond})
+	if err = m.Register(migrations...); err != nil {
+		os.RemoveAll(strDir)
+		t.Fatalf("register error (%v)", err)
+	}
+	done = func() {
+		os.RemoveAll(strDir)
+	}
+	return
+}
+
+func testMigrations() []*Migration {
+	return []*Migration{
+		{Version: 1, Name: "create_users", UpSQL: "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL DEFAULT '');", DownSQL: "DROP TABLE users;"},
+		{Version: 2, Name: "add_user", Up: func(tx *sqlca.Engine) error {
+			_, _, err := tx.TxExec("INSERT INTO users (id, name) VALUES (1, 'a')")
+			return err
+		}, Down: func(tx *sqlca.Engine) error {
+			_, _, err := tx.TxExec("DELETE FROM users WHERE id=1")
+			return err
+		}},
+		{Version: 3, Name: "create_classes", UpSQL: "CREATE TABLE classes (id INTEGER PRIMARY KEY);\n-- comment only\n", DownSQL: "DROP TABLE classes"},
+	}
+}
+
+func getApplied(t *testing.T, m *Migrator) (versions []int64) {
+	status, err := m.Status()
+	if err != nil {
+		t.Fatalf("status error (%v)", err)
+	}
+	for _, v := range status {
+		if v.Applied {
+			versions = append(versions, v.Version)
+		}
+	}
+	return
+}
+
+func TestMigrateUpDown(t *testing.T) {
+
+	m, done := newTestMigrator(t, testMigrations()...)
+	defer done()
+
+	if err := m.Up(2); err != nil {
+		t.Fatalf("up 2 steps error (%v)", err)
+	}
+	if v := getApplied(t, m); !reflect.DeepEqual(v, []int64{1, 2}) {
+		t.Errorf("versions [1 2] applied expected, got %v", v)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("up error (%v)", err)
+	}
+	if err := m.Up(); err != ErrNoChange {
+		t.Errorf("ErrNoChange expected, got %v", err)
+	}
+	var count int64
+	if _, err := m.engine.Model(&count).QueryRaw("SELECT COUNT(*) FROM users"); err != nil || count != 1 {
+		t.Errorf("1 user inserted by go function expected, got %v error %v", count, err)
+	}
+
+	if err := m.Down(); err != nil {
+		t.Fatalf("down error (%v)", err)
+	}
+	if v := getApplied(t, m); !reflect.DeepEqual(v, []int64{1, 2}) {
+		t.Errorf("versions [1 2] applied expected, got %v", v)
+	}
+	if err := m.Down(0); err != nil {
+		t.Fatalf("down all error (%v)", err)
+	}
+	if v := getApplied(t, m); len(v) != 0 {
+		t.Errorf("no version applied expected, got %v", v)
+	}
+	if err := m.Down(); err != ErrNoChange {
+		t.Errorf("ErrNoChange expected, got %v", err)
+	}
+}
+
+func TestMigrateRegister(t *testing.T) {
+
+	m, done := newTestMigrator(t)
+	defer done()
+
+	if err := m.Register(&Migration{Version: 0, Name: "zero"}); err == nil {
+		t.Errorf("version 0 must be refused")
+	}
+	if err := m.Register(&Migration{Version: 1, Name: "a"}, &Migration{Version: 1, Name: "b"}); err == nil {
+		t.Errorf("duplicated version must be refused")
+	}
+	v := &Migration{Version: 2, Name: "c", UpSQL: "SELECT 1"}
+	if err := m.Register(v); err != nil || v.Checksum != makeChecksum("SELECT 1", "") {
+		t.Errorf("checksum of SQL expected, got %v error %v", v.Checksum, err)
+	}
+}
+
+func TestMigrateChecksumAndDirty(t *testing.T) {
+
+	migrations := testMigrations()
+	m, done := newTestMigrator(t, migrations[0])
+	defer done()
+
+	if err := m.Up(); err != nil {
+		t.Fatalf("up error (%v)", err)
+	}
+	migrations[0].UpSQL += " -- changed"
+	migrations[0].Checksum = makeChecksum(migrations[0].UpSQL, migrations[0].DownSQL)
+	if err := m.Up(); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("ErrChecksumMismatch expected, got %v", err)
+	}
+	status, _ := m.Status()
+	if len(status) != 1 || !status[0].Modified {
+		t.Errorf("modified status expected, got %+v", status)
+	}
+
+	//sqlite rolls back DDL, so the failed version is not left dirty
+	bad := &Migration{Version: 5, Name: "bad", UpSQL: "CREATE TABLE t5 (id INTEGER); INSERT INTO no_such_table VALUES (1)"}
+	m2, done2 := newTestMigrator(t, bad)
+	defer done2()
+	if err := m2.Up(); err == nil {
+		t.Fatalf("bad migration must fail")
+	}
+	if v := getApplied(t, m2); len(v) != 0 {
+		t.Errorf("failed version must be removed, got %v", v)
+	}
+
+	//a dirty version refuses migrations until forced
+	if _, _, err := m2.engine.ExecRaw(fmt.Sprintf("INSERT INTO %v (version,name,checksum,dirty,applied_at) VALUES (5,'bad','',1,'')", DEFAULT_MIGRATIONS_TABLE)); err != nil {
+		t.Fatalf("insert dirty version error (%v)", err)
+	}
+	if err := m2.Up(); !errors.Is(err, ErrDirty) {
+		t.Errorf("ErrDirty expected, got %v", err)
+	}
+	if err := m2.Force(5); err != nil {
+		t.Fatalf("force error (%v)", err)
+	}
+	if err := m2.Up(); err != ErrNoChange {
+		t.Errorf("ErrNoChange expected after forced, got %v", err)
+	}
+}
+
+func TestMigrateLock(t *testing.T) {
+
+	m, done := newTestMigrator(t, testMigrations()...)
+	defer done()
+
+	other := NewMigrator(m.engine, &Options{LockTimeout: time.Millisecond})
+	if err := other.prepare(); err != nil {
+		t.Fatalf("lock error (%v)", err)
+	}
+	if err := m.Up(); err != ErrLocked {
+		t.Errorf("ErrLocked expected, got %v", err)
+	}
+	other.unlock()
+	if err := m.Up(1); err != nil {
+		t.Fatalf("up after unlocked error (%v)", err)
+	}
+
+	//the lock of a crashed instance expires after LockTTL
+	if _, _, err := m.engine.ExecRaw(fmt.Sprintf("INSERT INTO %v (id,owner,locked_at) VALUES (1,'crashed',%v)",
+		m.lockTable(), time.Now().Add(-2*DEFAULT_LOCK_TTL).Unix())); err != nil {
+		t.Fatalf("insert expired lock error (%v)", err)
+	}
+	if err := m.Up(1); err != nil {
+		t.Errorf("expired lock must be taken, got %v", err)
+	}
+
+	//renew fails if the lock was taken by another instance
+	if err := m.lock(); err != nil {
+		t.Fatalf("lock error (%v)", err)
+	}
+	if err := m.renewLock(); err != nil {
+		t.Errorf("renew own lock error (%v)", err)
+	}
+	if _, _, err := m.engine.ExecRaw(fmt.Sprintf("UPDATE %v SET owner='other' WHERE id=1", m.lockTable())); err != nil {
+		t.Fatalf("steal lock error (%v)", err)
+	}
+	if err := m.renewLock(); err != ErrLockLost {
+		t.Errorf("ErrLockLost expected, got %v", err)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+
+	strDir, err := ioutil.TempDir("", "migrate")
+	if err != nil {
+		t.Fatalf("make temp dir error (%v)", err)
+	}
+	defer os.RemoveAll(strDir)
+
+	strUp, strDown, err := Create(strDir, "create users!")
+	if err != nil {
+		t.Fatalf("create error (%v)", err)
+	}
+	if !regexMigrationFile.MatchString(filepath.Base(strUp)) || !regexMigrationFile.MatchString(filepath.Base(strDown)) {
+		t.Errorf("migration file names expected, got %v %v", strUp, strDown)
+	}
+	_ = ioutil.WriteFile(filepath.Join(strDir, "README.md"), []byte("not a migration"), 0644)
+	_ = ioutil.WriteFile(filepath.Join(strDir, "1_init.up.sql"), []byte("CREATE TABLE a (id INT);"), 0644)
+
+	migrations, err := LoadDir(strDir)
+	if err != nil {
+		t.Fatalf("load dir error (%v)", err)
+	}
+	if len(migrations) != 2 || migrations[0].Version != 1 || migrations[0].Name != "init" || migrations[1].Name != "create_users_" {
+		t.Fatalf("2 sorted migrations expected, got %+v %+v", migrations[0], migrations[1])
+	}
+	if migrations[0].Checksum != makeChecksum("CREATE TABLE a (id INT);", "") {
+		t.Errorf("checksum of files expected")
+	}
+
+	_ = ioutil.WriteFile(filepath.Join(strDir, "1_other.down.sql"), []byte(""), 0644)
+	if _, err = LoadDir(strDir); err == nil {
+		t.Errorf("duplicated version of different names must fail")
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+
+	var cases = []struct {
+		strSQL string
+		expect []string
+	}{
+		{"SELECT 1; SELECT 2;", []string{"SELECT 1", "SELECT 2"}},
+		{"INSERT INTO a VALUES ('x;y', \"z;\", `c;`);", []string{"INSERT INTO a VALUES ('x;y', \"z;\", `c;`)"}},
+		{"-- a; comment\nSELECT 1; /* b; */ SELECT 2", []string{"-- a; comment\nSELECT 1", "/* b; */ SELECT 2"}},
+		{"CREATE FUNCTION f() RETURNS int AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql;", []string{"CREATE FUNCTION f() RETURNS int AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql"}},
+		{"-- only comment\n;", nil},
+	}
+	for i, v := range cases {
+		if s := SplitStatements(v.strSQL); !reflect.DeepEqual(s, v.expect) {
+			t.Errorf("cases[%v]: %q expected, got %q", i, v.expect, s)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package migrate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	MIGRATION_FILE_UP   = ".up.sql"
+	MIGRATION_FILE_DOWN = ".down.sql"
+	MIGRATION_VERSION   = "20060102150405" //version format of created migration
+)
+
+var regexMigrationFile = regexp.MustCompile(`^(\d+)_(.+?)\.(up|down)\.sql$`)
+
+// load migrations from files named like 20200316103000_create_users.up.sql and 20200316103000_create_users.down.sql
+func LoadDir(strDir string) (migrations []*Migration, err error) {
+
+	var files []os.FileInfo
+	if files, err = ioutil.ReadDir(strDir); err != nil {
+		return
+	}
+	var dict = make(map[int64]*Migration)
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		match := regexMigrationFile.FindStringSubmatch(f.Name())
+		if match == nil {
+			continue
+		}
+		var version int64
+		if version, err = strconv.ParseInt(match[1], 10, 64); err != nil {
+			return nil, fmt.Errorf("migration file [%v] version error [%w]", f.Name(), err)
+		}
+		var data []byte
+		if data, err = ioutil.ReadFile(filepath.Join(strDir, f.Name())); err != nil {
+			return
+		}
+		v, ok := dict[version]
+		if !ok {
+			v = &Migration{Version: version, Name: match[2]}
+			dict[version] = v
+		} else if v.Name != match[2] {
+			return nil, fmt.Errorf("migration version [%v] duplicated by [%v] and [%v]", version, v.Name, match[2])
+		}
+		if match[3] == "up" {
+			v.UpSQL = string(data)
+		} else {
+			v.DownSQL = string(data)
+		}
+	}
+	for _, v := range dict {
+		v.Checksum = makeChecksum(v.UpSQL, v.DownSQL)
+		migrations = append(migrations, v)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return
+}
+
+// create empty up and down files of a new version in directory
+func Create(strDir, strName string) (strUpFile, strDownFile string, err error) {
+
+	strName = strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(strName))
+	if strName == "" {
+		return "", "", fmt.Errorf("migration name is empty")
+	}
+	if err = os.MkdirAll(strDir, 0755); err != nil {
+		return
+	}
+	strPrefix := filepath.Join(strDir, fmt.Sprintf("%v_%v", time.Now().Format(MIGRATION_VERSION), strName))
+	strUpFile, strDownFile = strPrefix+MIGRATION_FILE_UP, strPrefix+MIGRATION_FILE_DOWN
+	if err = ioutil.WriteFile(strUpFile, []byte("-- "+strName+" up\n"), 0644); err != nil {
+		return
+	}
+	err = ioutil.WriteFile(strDownFile, []byte("-- "+strName+" down\n"), 0644)
+	return
+}
+
+// split SQL into statements by ';' out of quotes, comments and $$ blocks (postgres functions)
+func SplitStatements(strSQL string) (statements []string) {
+
+	var sb strings.Builder
+	var quote byte       // ' " `
+	var dollar bool      // in $$ block
+	var lineComment bool // -- comment
+	var blockComment bool
+	flush := func() {
+		if s := strings.TrimSpace(sb.String()); s != "" && !isCommentOnly(s) {
+			statements = append(statements, s)
+		}
+		sb.Reset()
+	}
+	for i := 0; i < len(strSQL); i++ {
+		c := strSQL[i]
+		var next byte
+		if i+1 < len(strSQL) {
+			next = strSQL[i+1]
+		}
+		switch {
+		case lineComment:
+			if c == '\n' {
+				lineComment = false
+			}
+		case blockComment:
+			if c == '*' && next == '/' {
+				blockComment = false
+				sb.WriteByte(c)
+				i++
+				c = next
+			}
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case dollar:
+			if c == '$' && next == '$' {
+				dollar = false
+				sb.WriteByte(c)
+				i++
+				c = next
+			}
+		case c == '-' && next == '-':
+			lineComment = true
+		case c == '/' && next == '*':
+			blockComment = true
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '$' && next == '$':
+			dollar = true
+			sb.WriteByte(c)
+			i++
+			c = next
+		case c == ';':
+			flush()
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	flush()
+	return
+}
+
+func isCommentOnly(s string) bool {
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
@@ -3,17 +3,18 @@ package sqlca
 import (
 	"database/sql"
 	"fmt"
-	"github.com/civet148/gotools/log"
 	"github.com/shopspring/decimal"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type ModelReflector struct {
 	value  interface{}            //value
 	engine *Engine                // database engine
 	dict   map[string]interface{} //dictionary of structure tag and value
+	nils   []string               //tag values of nil pointer fields (select only)
 }
 
 type Fetcher struct {
@@ -22,6 +23,7 @@ type Fetcher struct {
 	types     []*sql.ColumnType //column types in db table
 	arrValues [][]byte          //value slice
 	mapValues map[string]string //value map
+	mapNulls  map[string]bool   //columns of NULL value
 	arrIndex  int               //fetch index
 }
 
@@ -75,7 +77,7 @@ func (s *ModelReflector) ToMap(tagNames ...string) map[string]interface{} {
 		val = reflect.New(typ).Elem()
 		s.parseStructField(typ, val, tagNames...)
 	} else {
-		log.Warnf("kind [%v] not support yet", typ.Kind())
+		s.engine.warnf("kind [%v] not support yet", typ.Kind())
 	}
 	return s.dict
 }
@@ -101,6 +103,10 @@ func (s *ModelReflector) parseStructField(typ reflect.Type, val reflect.Value, t
 			valField := val.Field(i)
 
 			if typField.Type.Kind() == reflect.Ptr {
+				if valField.IsNil() {
+					s.setNilField(typField, tagNames...)
+					continue
+				}
 				typField.Type = typField.Type.Elem()
 				valField = valField.Elem()
 			}
@@ -112,6 +118,8 @@ func (s *ModelReflector) parseStructField(typ reflect.Type, val reflect.Value, t
 
 				if d, ok := valField.Interface().(Decimal); ok {
 					s.parseDecimal(typField, valField, d, tagNames...) //decimal struct
+				} else if typField.Type == typeOfTime {
+					s.setValueByField(typField, valField, tagNames...) //time.Time struct
 				} else {
 					s.parseStructField(typField.Type, valField, tagNames...) //recurse every field that type is a struct
 				}
@@ -122,6 +130,25 @@ func (s *ModelReflector) parseStructField(typ reflect.Type, val reflect.Value, t
 	}
 }
 
+// nil pointer field (NULL value) is selected but never inserted or updated
+func (s *ModelReflector) setNilField(field reflect.StructField, tagNames ...string) {
+
+	for _, v := range tagNames {
+		if v == TAG_NAME_SQLCA {
+			continue
+		}
+		if tagVal := handleTagValue(v, s.getTag(field, v)); tagVal != "" {
+			s.nils = append(s.nils, tagVal)
+			return
+		}
+	}
+}
+
+// tag values of nil pointer fields, must be called after ToMap
+func (s *ModelReflector) getNilFields() []string {
+	return s.nils
+}
+
 //parse decimal
 func (s *ModelReflector) parseDecimal(field reflect.StructField, val reflect.Value, d Decimal, tagNames ...string) {
 
@@ -132,7 +159,7 @@ func (s *ModelReflector) parseDecimal(field reflect.StructField, val reflect.Val
 func (s *ModelReflector) setValueByField(field reflect.StructField, val reflect.Value, tagNames ...string) {
 
 	if len(tagNames) == 0 {
-		log.Errorf("ModelReflector.setValueByField no tag to set value")
+		s.engine.errorf("ModelReflector.setValueByField no tag to set value")
 		return
 	}
 
@@ -148,6 +175,13 @@ func (s *ModelReflector) setValueByField(field reflect.StructField, val reflect.
 			//log.Debugf("ModelReflector.setValueByField tag [%v] value [%+v]", tagVal, val.Interface())
 			if d, ok := val.Interface().(Decimal); ok {
 				s.dict[tagVal] = d.dec.String()
+			} else if t, ok := val.Interface().(time.Time); ok {
+				s.dict[tagVal] = fmtTimeValue(t)
+			} else if val.Kind() == reflect.Bool {
+				s.dict[tagVal] = 0 //bool as 1/0 for tinyint(1)/bit column
+				if val.Bool() {
+					s.dict[tagVal] = 1
+				}
 			} else {
 				s.dict[tagVal] = val.Interface()
 			}
@@ -162,7 +196,7 @@ func (s *ModelReflector) setValueByField(field reflect.StructField, val reflect.
 			vs := strings.Split(strTagValue, ",")
 			for _, vv := range vs {
 				if vv == SQLCA_TAG_VALUE_READ_ONLY { //column is read only
-					s.engine.readOnly = append(s.engine.readOnly, tagVal)
+					s.engine.readOnly = append(s.engine.readOnly[:len(s.engine.readOnly):len(s.engine.readOnly)], tagVal)
 					//log.Debugf("%v [%v]", tagVal, SQLCAL_TAG_VALUE_READ_ONLY)
 				}
 			}
@@ -177,12 +211,12 @@ func (e *Engine) fetchRows(r *sql.Rows) (count int64, err error) {
 
 		if e.getModelType() == ModelType_BaseType {
 			if c, err = e.fetchRow(r, e.model.([]interface{})...); err != nil {
-				log.Errorf("fetchRow error [%v]", err.Error())
+				e.errorf("fetchRow error [%v]", err.Error())
 				return
 			}
 		} else {
 			if c, err = e.fetchRow(r, e.model); err != nil {
-				log.Errorf("fetchRow error [%v]", err.Error())
+				e.errorf("fetchRow error [%v]", err.Error())
 				return
 			}
 		}
@@ -318,9 +352,17 @@ func (e *Engine) getStructFieldValues(typ reflect.Type, val reflect.Value, exclu
 			}
 			strTagVal := e.getTagValue(typField)
 			strFieldVal := fmt.Sprintf("%v", valField)
+			if t, ok := valField.Interface().(time.Time); ok {
+				strFieldVal = t.Format(DATETIME_FORMAT)
+			} else if valField.Kind() == reflect.Bool {
+				strFieldVal = "0"
+				if valField.Bool() {
+					strFieldVal = "1"
+				}
+			}
 
 			if excludeReadOnly {
-				if typField.Tag.Get(TAG_NAME_SQLCA) == SQLCA_TAG_VALUE_READ_ONLY {
+				if hasSqlcaTagValue(typField, SQLCA_TAG_VALUE_READ_ONLY) || hasSqlcaTagValue(typField, SQLCA_TAG_VALUE_SOFT_DELETE) {
 					continue
 				}
 			}
@@ -405,6 +447,7 @@ func (e *Engine) getFecther(rows *sql.Rows) (fetcher *Fetcher, err error) {
 	fetcher.types, _ = rows.ColumnTypes()
 	fetcher.arrValues = make([][]byte, fetcher.count)
 	fetcher.mapValues = make(map[string]string)
+	fetcher.mapNulls = make(map[string]bool)
 	scans := make([]interface{}, fetcher.count)
 
 	for i := range fetcher.arrValues {
@@ -418,6 +461,9 @@ func (e *Engine) getFecther(rows *sql.Rows) (fetcher *Fetcher, err error) {
 	for i, v := range fetcher.arrValues {
 
 		fetcher.mapValues[fetcher.cols[i]] = string(v)
+		if v == nil {
+			fetcher.mapNulls[fetcher.cols[i]] = true
+		}
 	}
 	return
 }
@@ -454,18 +500,21 @@ func (e *Engine) fetchToStruct(fetcher *Fetcher, typ reflect.Type, val reflect.V
 			valField := val.Field(i)
 
 			if typField.Type.Kind() == reflect.Ptr {
+				e.fetchToPtr(fetcher, typField, valField)
 				typField.Type = typField.Type.Elem()
 				valField = valField.Elem()
 			}
 			if !valField.IsValid() || !valField.CanInterface() {
 				//fmt.Printf("Filed [%s] tag(%s)  is not valid \n", typField.Type.Name(), e.getTagValue(typField))
-				return
+				continue //nil pointer of NULL value
 			}
 			switch typField.Type.Kind() {
 			case reflect.Struct:
 				{
 					if _, ok := valField.Interface().(Decimal); ok {
 						e.fetchToDecimal(fetcher, typField, valField)
+					} else if typField.Type == typeOfTime {
+						e.fetchToTime(fetcher, typField, valField)
 					} else {
 						e.fetchToStruct(fetcher, typField.Type, valField)
 					}
@@ -481,6 +530,22 @@ func (e *Engine) fetchToStruct(fetcher *Fetcher, typ reflect.Type, val reflect.V
 	return
 }
 
+// allocate pointer field if column value is not NULL or set it to nil if NULL
+func (e *Engine) fetchToPtr(fetcher *Fetcher, field reflect.StructField, val reflect.Value) {
+	strDbTagVal := e.getTagValue(field)
+	if strDbTagVal == "" || strDbTagVal == SQLCA_TAG_VALUE_IGNORE || !val.CanSet() {
+		return
+	}
+	if _, ok := fetcher.mapValues[strDbTagVal]; !ok {
+		return
+	}
+	if fetcher.mapNulls[strDbTagVal] {
+		val.Set(reflect.Zero(field.Type))
+	} else if val.IsNil() {
+		val.Set(reflect.New(field.Type.Elem()))
+	}
+}
+
 func (e *Engine) fetchToDecimal(fetcher *Fetcher, field reflect.StructField, val reflect.Value) {
 	//优先给有db标签的成员变量赋值
 	strDbTagVal := e.getTagValue(field)
@@ -495,6 +560,18 @@ func (e *Engine) fetchToDecimal(fetcher *Fetcher, field reflect.StructField, val
 	}
 }
 
+func (e *Engine) fetchToTime(fetcher *Fetcher, field reflect.StructField, val reflect.Value) {
+	strDbTagVal := e.getTagValue(field)
+	if strDbTagVal == SQLCA_TAG_VALUE_IGNORE {
+		return
+	}
+	if v, ok := fetcher.mapValues[strDbTagVal]; ok {
+		if t, ok := parseTimeValue(v); ok {
+			val.Set(reflect.ValueOf(t))
+		}
+	}
+}
+
 func (e *Engine) fetchToBaseType(fetcher *Fetcher, typ reflect.Type, val reflect.Value) (err error) {
 
 	v := fetcher.arrValues[fetcher.arrIndex]
@@ -575,3 +652,76 @@ func (e *Engine) setValue(typ reflect.Type, val reflect.Value, v string) {
 		return
 	}
 }
+
+// validate struct model or every element of slice model by validate tag before insert/upsert/update
+// only selected columns are validated when update
+func (e *Engine) validateModel() error {
+
+	if e.model == nil {
+		return nil
+	}
+	val := reflect.ValueOf(e.model)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	var errs ValidationErrors
+	switch val.Kind() {
+	case reflect.Struct:
+		errs = e.validateStruct(val)
+	case reflect.Slice:
+		for i := 0; i < val.Len(); i++ {
+			elem := val.Index(i)
+			for elem.Kind() == reflect.Ptr && !elem.IsNil() {
+				elem = elem.Elem()
+			}
+			if elem.Kind() == reflect.Struct {
+				errs = append(errs, e.validateStruct(elem)...)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (e *Engine) validateStruct(val reflect.Value) (errs ValidationErrors) {
+
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		typField := typ.Field(i)
+		valField := val.Field(i)
+		if !valField.CanInterface() {
+			continue
+		}
+		strTag := typField.Tag.Get(TAG_NAME_VALIDATE)
+		if strTag == "" || strTag == SQLCA_TAG_VALUE_IGNORE {
+			elemTyp := typField.Type
+			if elemTyp.Kind() == reflect.Ptr {
+				elemTyp = elemTyp.Elem()
+			}
+			if elemTyp.Kind() == reflect.Struct && elemTyp != typeOfTime {
+				if _, ok := reflect.Zero(elemTyp).Interface().(Decimal); ok {
+					continue
+				}
+				if valField.Kind() == reflect.Ptr {
+					if valField.IsNil() {
+						continue
+					}
+					valField = valField.Elem()
+				}
+				errs = append(errs, e.validateStruct(valField)...) //recurse nested struct
+			}
+			continue
+		}
+		strColumn := e.getTagValue(typField)
+		if e.operType == OperType_Update && (!e.isColumnSelected(strColumn) || e.isReadOnly(strColumn)) {
+			continue
+		}
+		errs = append(errs, validateField(typField, valField, strColumn, parseValidateRules(strTag))...)
+	}
+	return
+}
@@ -0,0 +1,168 @@
+package sqlca
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type SlowLogOptions struct {
+	SampleRate float64   // sample rate of slow statements (0,1], default 1 (log every slow statement)
+	Explain    bool      // capture EXPLAIN for slow SELECT statements
+	Writer     io.Writer // slow log sink, default os.Stderr
+}
+
+// slow statement entry, written as one json line
+type SlowLogEntry struct {
+	Time     string              `json:"time"`              // datetime of statement finished
+	OperType string              `json:"oper_type"`         // operation type
+	Table    string              `json:"table"`             // table name
+	SQL      string              `json:"sql"`               // final sql
+	Duration string              `json:"duration"`          // execute duration
+	Millis   float64             `json:"millis"`            // execute duration of milliseconds
+	Rows     int64               `json:"rows"`              // rows affected or fetched
+	Node     string              `json:"node"`              // master or slave
+	Caller   string              `json:"caller"`            // caller file:line
+	Func     string              `json:"func"`              // caller function name
+	Error    string              `json:"error,omitempty"`   // execute error
+	Explain  []map[string]string `json:"explain,omitempty"` // EXPLAIN results for slow SELECT
+}
+
+type slowLogger struct {
+	engine    *Engine
+	locker    sync.Mutex
+	threshold time.Duration
+	options   SlowLogOptions
+}
+
+// log every statement which execute duration over threshold to slow log sink
+// threshold <= 0 will disable slow log
+// this function must calls before Model()
+func (e *Engine) SetSlowThreshold(threshold time.Duration, options ...*SlowLogOptions) *Engine {
+
+	var opts SlowLogOptions
+	if len(options) > 0 && options[0] != nil {
+		opts = *options[0]
+	}
+	if opts.SampleRate <= 0 || opts.SampleRate > 1 {
+		opts.SampleRate = 1
+	}
+	if opts.Writer == nil {
+		opts.Writer = os.Stderr
+	}
+
+	if e.slowLog == nil {
+		e.slowLog = &slowLogger{engine: e}
+		e.Use(e.slowLog.intercept)
+	}
+	e.slowLog.locker.Lock()
+	e.slowLog.threshold = threshold
+	e.slowLog.options = opts
+	e.slowLog.locker.Unlock()
+	return e
+}
+
+func (s *slowLogger) intercept(stmt *Statement, next Invoker) error {
+
+	err := next(stmt)
+
+	s.locker.Lock()
+	threshold, opts := s.threshold, s.options
+	s.locker.Unlock()
+
+	if threshold <= 0 || stmt.Duration < threshold {
+		return err
+	}
+	if opts.SampleRate < 1 && rand.Float64() >= opts.SampleRate {
+		return err
+	}
+
+	strFile, strFunc, nLine := getOuterCaller()
+	entry := &SlowLogEntry{
+		Time:     time.Now().Format("2006-01-02 15:04:05.000"),
+		OperType: stmt.OperType.String(),
+		Table:    stmt.TableName,
+		SQL:      stmt.SQL,
+		Duration: stmt.Duration.String(),
+		Millis:   float64(stmt.Duration) / float64(time.Millisecond),
+		Rows:     stmt.RowsAffected,
+		Node:     stmt.Node,
+		Caller:   fmt.Sprintf("%v:%v", strFile, nLine),
+		Func:     strFunc,
+	}
+	if stmt.Error != nil {
+		entry.Error = stmt.Error.Error()
+	}
+	if opts.Explain && stmt.Error == nil && isSelectStatement(stmt) {
+		entry.Explain = s.explain(stmt)
+	}
+	s.write(opts.Writer, entry)
+	return err
+}
+
+func (s *slowLogger) write(w io.Writer, entry *SlowLogEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		s.engine.errorf("marshal slow log entry error [%v]", err.Error())
+		return
+	}
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	if _, err = w.Write(append(data, '\n')); err != nil {
+		s.engine.errorf("write slow log entry error [%v]", err.Error())
+	}
+}
+
+func isSelectStatement(stmt *Statement) bool {
+	switch stmt.OperType {
+	case OperType_Query, OperType_QueryRaw, OperType_QueryMap:
+		return strings.HasPrefix(strings.ToUpper(trimSqlComment(stmt.SQL)), DATABASE_KEY_NAME_SELECT)
+	}
+	return false
+}
+
+// run EXPLAIN directly on the node (not intercepted)
+func (s *slowLogger) explain(stmt *Statement) (results []map[string]string) {
+
+	e := s.engine
+	var strExplain string
+	switch e.adapterSqlx {
+	case AdapterSqlx_MySQL, AdapterSqlx_Postgres:
+		strExplain = "EXPLAIN " + stmt.SQL
+	case AdapterSqlx_Sqlite:
+		strExplain = "EXPLAIN QUERY PLAN " + stmt.SQL
+	default:
+		return //mssql need SET SHOWPLAN_TEXT ON in session, not support
+	}
+
+	var db *sqlx.DB
+	if stmt.Node == NODE_NAME_SLAVE {
+		db = e.getSlave()
+	} else {
+		db = e.getMaster()
+	}
+	if db == nil {
+		return
+	}
+	rows, err := db.Queryx(strExplain)
+	if err != nil {
+		e.warnf("explain [%v] error [%v]", strExplain, err.Error())
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		fetcher, err := e.getFecther(rows.Rows)
+		if err != nil {
+			e.warnf("explain [%v] fetch error [%v]", strExplain, err.Error())
+			return
+		}
+		results = append(results, fetcher.mapValues)
+	}
+	return
+}
@@ -0,0 +1,104 @@
+package sqlca
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readSlowLog(t *testing.T, buf *bytes.Buffer) (entries []SlowLogEntry) {
+	for _, v := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if v == "" {
+			continue
+		}
+		var entry SlowLogEntry
+		if err := json.Unmarshal([]byte(v), &entry); err != nil {
+			t.Fatalf("unmarshal slow log [%v] error (%v)", v, err)
+		}
+		entries = append(entries, entry)
+	}
+	buf.Reset()
+	return
+}
+
+func TestSlowLog(t *testing.T) {
+
+	e, done := newTestEngine(t, testUsersDDL, "INSERT INTO users (name, age) VALUES ('a', 1)")
+	defer done()
+
+	var buf bytes.Buffer
+	e.SetSlowThreshold(time.Nanosecond, &SlowLogOptions{Writer: &buf, Explain: true})
+
+	var users []testUser
+	if _, err := e.Model(&users).Table("users").Where("age > 0").Query(); err != nil {
+		t.Fatalf("query error (%v)", err)
+	}
+	entries := readSlowLog(t, &buf)
+	if len(entries) != 1 {
+		t.Fatalf("1 slow log entry expected, got %v", len(entries))
+	}
+	entry := entries[0]
+	if entry.OperType != OperType_Query.String() || entry.Table != "users" || entry.Node != NODE_NAME_MASTER || entry.Rows != 1 {
+		t.Errorf("query of users on master expected, got %+v", entry)
+	}
+	if !strings.HasPrefix(entry.SQL, "SELECT") || entry.Millis <= 0 || entry.Duration == "" {
+		t.Errorf("sql and duration expected, got %+v", entry)
+	}
+	//test functions are in package sqlca, the outer caller is the test runner
+	if strings.HasPrefix(entry.Func, "sqlca.") || entry.Caller == "" {
+		t.Errorf("caller out of sqlca expected, got %v %v", entry.Caller, entry.Func)
+	}
+	if len(entry.Explain) == 0 {
+		t.Errorf("explain of slow select expected")
+	}
+
+	if _, err := e.Model(&users).Table("no_such_table").Query(); err == nil {
+		t.Fatalf("query of no such table must fail")
+	}
+	if entries = readSlowLog(t, &buf); len(entries) != 1 || entries[0].Error == "" || entries[0].Explain != nil {
+		t.Errorf("error without explain expected, got %+v", entries)
+	}
+
+	//no explain for writes
+	user := testUser{Name: "b", Age: 2}
+	if _, err := e.Model(&user).Table("users").Insert(); err != nil {
+		t.Fatalf("insert error (%v)", err)
+	}
+	if entries = readSlowLog(t, &buf); len(entries) != 1 || entries[0].OperType != OperType_Insert.String() || entries[0].Explain != nil {
+		t.Errorf("insert without explain expected, got %+v", entries)
+	}
+}
+
+func TestSlowLogThreshold(t *testing.T) {
+
+	e, done := newTestEngine(t, testUsersDDL)
+	defer done()
+
+	var buf bytes.Buffer
+	var users []testUser
+	for _, v := range []time.Duration{time.Hour, 0} {
+		e.SetSlowThreshold(v, &SlowLogOptions{Writer: &buf})
+		if _, err := e.Model(&users).Table("users").Query(); err != nil {
+			t.Fatalf("query error (%v)", err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("threshold %v: no slow log expected, got %v", v, buf.String())
+		}
+	}
+	if n := len(e.interceptors); n != 1 {
+		t.Errorf("slow log interceptor must be added once, got %v", n)
+	}
+
+	//sample rate out of (0,1] logs every slow statement
+	e.SetSlowThreshold(time.Nanosecond, &SlowLogOptions{Writer: &buf, SampleRate: 2})
+	for i := 0; i < 3; i++ {
+		if _, err := e.Model(&users).Table("users").Query(); err != nil {
+			t.Fatalf("query error (%v)", err)
+		}
+	}
+	if entries := readSlowLog(t, &buf); len(entries) != 3 {
+		t.Errorf("3 slow log entries expected, got %v", len(entries))
+	}
+}
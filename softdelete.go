@@ -0,0 +1,112 @@
+package sqlca
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// soft delete column parsed from struct tag sqlca:"softdelete"
+//
+// column type             not deleted          deleted
+// bool/int8/uint8         col=0                col=1
+// other integer/float     col=0                col=<unix seconds>
+// string/time/pointer     col IS NULL          col='2006-01-02 15:04:05'
+type softDeleteColumn struct {
+	Name     string       // column name
+	Type     reflect.Type // field type (pointer element type if nullable)
+	Nullable bool         // field is a pointer or not integer type
+}
+
+// disable soft delete predicate and delete records permanently
+func (e *Engine) Unscoped() *Engine {
+	c := e.copy()
+	c.bUnscoped = true
+	return c
+}
+
+// delete records permanently even model has a soft delete column
+func (e *Engine) HardDelete() (rowsAffected int64, err error) {
+	return e.Unscoped().Delete()
+}
+
+// check field's sqlca tag contains option (eg. sqlca:"readonly,softdelete")
+func hasSqlcaTagValue(sf reflect.StructField, strOpt string) bool {
+	for _, v := range splitSqlcaTag(sf.Tag.Get(TAG_NAME_SQLCA)) {
+		if v == strOpt {
+			return true
+		}
+	}
+	return false
+}
+
+// find soft delete column from model type, nil pointer fields are included
+func (e *Engine) parseSoftDelete(typ reflect.Type) *softDeleteColumn {
+
+	for _, sf := range e.getSqlcaTagFields(typ, SQLCA_TAG_VALUE_SOFT_DELETE) {
+		strName := e.getTagValue(sf)
+		if strName == "" || strName == SQLCA_TAG_VALUE_IGNORE {
+			e.warnf("soft delete field [%v] have no db tag", sf.Name)
+			return nil
+		}
+		col := &softDeleteColumn{Name: strName, Type: sf.Type}
+		if sf.Type.Kind() == reflect.Ptr {
+			col.Type = sf.Type.Elem()
+			col.Nullable = true
+		}
+		switch col.Type.Kind() {
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		default:
+			col.Nullable = true
+		}
+		return col
+	}
+	return nil
+}
+
+func (e *Engine) setSoftDelete(model interface{}) {
+	if model == nil {
+		return
+	}
+	if col := e.parseSoftDelete(reflect.TypeOf(model)); col != nil {
+		e.softDelete = col
+	}
+}
+
+func (e *Engine) isSoftDelete() bool {
+	return e.softDelete != nil && !e.bUnscoped
+}
+
+func (e *Engine) isSoftDeleteColumn(strColumn string) bool {
+	return e.softDelete != nil && e.softDelete.Name == strColumn
+}
+
+// not deleted predicate, eg. `deleted_at` IS NULL or `is_deleted`=0
+func (e *Engine) getSoftDeleteWhere() (strCondition string) {
+	if e.softDelete.Nullable {
+		return fmt.Sprintf("%v %v", e.getQuoteColumnName(e.softDelete.Name), DATABASE_KEY_NAME_IS_NULL)
+	}
+	return fmt.Sprintf("%v=0", e.getQuoteColumnName(e.softDelete.Name))
+}
+
+// deleted value of soft delete column
+func (e *Engine) getSoftDeleteValue() interface{} {
+	switch e.softDelete.Type.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		return 1
+	case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return time.Now().Unix()
+	}
+	return time.Now().Format(DATETIME_FORMAT)
+}
+
+// UPDATE table SET `deleted_at`='2006-01-02 15:04:05' WHERE ... AND `deleted_at` IS NULL
+func (e *Engine) makeSqlxSoftDelete() (strSqlx string) {
+	strWhere := e.makeWhereCondition()
+	strSqlx = fmt.Sprintf("%v %v %v %v=%v %v",
+		DATABASE_KEY_NAME_UPDATE, e.getTableName(), DATABASE_KEY_NAME_SET,
+		e.getQuoteColumnName(e.softDelete.Name), e.getQuoteColumnValue(e.getSoftDeleteValue()), strWhere)
+	return
+}
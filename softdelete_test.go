@@ -0,0 +1,83 @@
+package sqlca
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPostsDDL = "CREATE TABLE posts (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT NOT NULL DEFAULT '', is_deleted INTEGER NOT NULL DEFAULT 0)"
+
+type softDeletePost struct {
+	Id        int64  `db:"id"`
+	Title     string `db:"title"`
+	IsDeleted int8   `db:"is_deleted" sqlca:"softdelete"`
+}
+
+type softDeleteUser struct {
+	Id        int64      `db:"id"`
+	Name      string     `db:"name"`
+	DeletedAt *time.Time `db:"deleted_at" sqlca:"softdelete"`
+}
+
+func TestSoftDelete(t *testing.T) {
+
+	e, done := newTestEngine(t, testPostsDDL, "INSERT INTO posts (title) VALUES ('a'),('b'),('c')")
+	defer done()
+
+	var post softDeletePost
+	if _, err := e.Model(&post).Table("posts").Id(1).Delete(); err != nil {
+		t.Fatalf("soft delete error (%v)", err)
+	}
+	var flag int8
+	if _, err := e.Model(&flag).QueryRaw("SELECT is_deleted FROM posts WHERE id=1"); err != nil || flag != 1 {
+		t.Fatalf("record must be kept and flagged, got flag %v error %v", flag, err)
+	}
+
+	var posts []softDeletePost
+	if n, err := e.Model(&posts).Table("posts").Query(); err != nil || n != 2 {
+		t.Errorf("query must skip deleted record, got %v rows error %v", n, err)
+	}
+	if n, err := e.Model(&posts).Table("posts").Count(); err != nil || n != 2 {
+		t.Errorf("count must skip deleted record, got %v error %v", n, err)
+	}
+	if n, err := e.Model(&posts).Table("posts").Unscoped().Count(); err != nil || n != 3 {
+		t.Errorf("unscoped count must include deleted record, got %v error %v", n, err)
+	}
+
+	post = softDeletePost{Id: 1, Title: "x"}
+	if n, err := e.Model(&post).Table("posts").Select("title").Update(); err != nil || n != 0 {
+		t.Errorf("update must skip deleted record, got %v rows error %v", n, err)
+	}
+
+	if _, err := e.Model(&post).Table("posts").Id(1).HardDelete(); err != nil {
+		t.Fatalf("hard delete error (%v)", err)
+	}
+	if n, err := e.Model(&posts).Table("posts").Unscoped().Count(); err != nil || n != 2 {
+		t.Errorf("record must be deleted permanently, got %v records error %v", n, err)
+	}
+}
+
+func TestSoftDeleteToSQL(t *testing.T) {
+
+	e, done := newTestEngine(t)
+	defer done()
+
+	const strPredicate = "`deleted_at` IS NULL"
+	user := softDeleteUser{Id: 1, Name: "a"}
+	for _, v := range []OperType{OperType_Query, OperType_ForUpdate, OperType_Update, OperType_Delete} {
+		strSql := e.Model(&user).Table("users").Select("name").ToSQL(v)
+		if !strings.Contains(strSql, strPredicate) {
+			t.Errorf("operation [%v]: %v expected, got [%v]", v, strPredicate, strSql)
+		}
+		if strSql = e.Model(&user).Table("users").Select("name").Unscoped().ToSQL(v); strings.Contains(strSql, strPredicate) {
+			t.Errorf("operation [%v]: unscoped must not have %v, got [%v]", v, strPredicate, strSql)
+		}
+	}
+	if strSql := e.Model(&user).Table("users").Id(1).ToSQL(OperType_Delete); !strings.HasPrefix(strSql, "UPDATE users SET `deleted_at`=") {
+		t.Errorf("soft delete expected, got [%v]", strSql)
+	}
+	if strSql := e.Model(&user).Table("users").Id(1).Unscoped().ToSQL(OperType_Delete); !strings.HasPrefix(strSql, "DELETE FROM users") {
+		t.Errorf("hard delete expected, got [%v]", strSql)
+	}
+}
@@ -3,7 +3,6 @@ package sqlca
 import (
 	"database/sql"
 	"fmt"
-	"github.com/civet148/gotools/log"
 	"github.com/jmoiron/sqlx"
 	"math/rand"
 	"reflect"
@@ -19,9 +18,13 @@ const (
 )
 
 const (
-	SQLCA_TAG_VALUE_AUTO_INCR = "autoincr" //auto increment
-	SQLCA_TAG_VALUE_READ_ONLY = "readonly" //read only (eg. created_at)
-	SQLCA_TAG_VALUE_IGNORE    = "-"        //ignore
+	SQLCA_TAG_VALUE_AUTO_INCR   = "autoincr"   //auto increment
+	SQLCA_TAG_VALUE_READ_ONLY   = "readonly"   //read only (eg. created_at)
+	SQLCA_TAG_VALUE_SOFT_DELETE = "softdelete" //soft delete (eg. deleted_at or is_deleted)
+	SQLCA_TAG_VALUE_CREATED     = "created"    //filled by insert/upsert (eg. created_at)
+	SQLCA_TAG_VALUE_UPDATED     = "updated"    //filled by insert/upsert/update (eg. updated_at)
+	SQLCA_TAG_VALUE_VERSION     = "version"    //optimistic locking version (eg. version)
+	SQLCA_TAG_VALUE_IGNORE      = "-"          //ignore
 )
 
 const (
@@ -56,6 +59,12 @@ const (
 	DATABASE_KEY_NAME_ASC        = "ASC"
 	DATABASE_KEY_NAME_DESC       = "DESC"
 	DATABASE_KEY_NAME_HAVING     = "HAVING"
+	DATABASE_KEY_NAME_IS_NULL    = "IS NULL"
+)
+
+const (
+	NODE_NAME_MASTER = "master"
+	NODE_NAME_SLAVE  = "slave"
 )
 
 type AdapterType int
@@ -215,12 +224,12 @@ func init() {
 
 func (e *Engine) appendMaster(db *sqlx.DB) {
 	e.dbMasters = append(e.dbMasters, db)
-	log.Debugf("db masters [%v]", len(e.dbMasters))
+	e.debugf("db masters [%v]", len(e.dbMasters))
 }
 
 func (e *Engine) appendSlave(db *sqlx.DB) {
 	e.dbSlaves = append(e.dbSlaves, db)
-	log.Debugf("db slaves [%v]", len(e.dbSlaves))
+	e.debugf("db slaves [%v]", len(e.dbSlaves))
 }
 
 // get slave db instance if use Slave() method to query, if not exist return a master db instance
@@ -234,6 +243,16 @@ func (e *Engine) getQueryDB() (db *sqlx.DB) {
 	return e.getMaster()
 }
 
+// get node name (master or slave) of a db instance
+func (e *Engine) getNodeName(db *sqlx.DB) string {
+	for _, v := range e.dbSlaves {
+		if v == db {
+			return NODE_NAME_SLAVE
+		}
+	}
+	return NODE_NAME_MASTER
+}
+
 // get a master db instance
 func (e *Engine) getMaster() *sqlx.DB {
 
@@ -271,7 +290,7 @@ func (e *Engine) setModel(models ...interface{}) *Engine {
 			e.setModelType(ModelType_Slice)
 		case reflect.Map: // map
 			//e.setModelType(ModelType_Map)
-			log.Errorf("map type model not support yet")
+			e.errorf("map type model not support yet")
 		default: //base type
 			e.setModelType(ModelType_BaseType)
 		}
@@ -281,10 +300,15 @@ func (e *Engine) setModel(models ...interface{}) *Engine {
 			e.model = models //base type argument like int/string/float32...
 		}
 		var selectColumns []string
-		e.dict = newReflector(e, e.model).ToMap(e.dbTags...)
+		reflector := newReflector(e, e.model)
+		e.dict = reflector.ToMap(e.dbTags...)
+		e.setSoftDelete(e.model)
+		e.setTimestamps(e.model)
+		e.setVersion(e.model)
 		for k, _ := range e.dict {
 			selectColumns = append(selectColumns, k)
 		}
+		selectColumns = append(selectColumns, reflector.getNilFields()...)
 		if len(selectColumns) == 0 {
 			e.setSelectColumns("*")
 		} else {
@@ -312,18 +336,31 @@ func (e *Engine) clone(models ...interface{}) *Engine {
 		dbTags:          e.dbTags,
 		bForce:          e.bForce,
 		bAutoRollback:   e.bAutoRollback,
+		interceptors:    e.interceptors,
+		metrics:         e.metrics,
+		slowLog:         e.slowLog,
+		logger:          e.logger,
+		bDebug:          e.bDebug,
+		ctx:             e.ctx,
 	}
 
 	engine.setModel(models...)
 	return engine
 }
 
+// shallow copy of builder (copy-on-write), slices must be appended by full slice expression s[:len(s):len(s)]
+// so the copies never share the appended elements
+func (e *Engine) copy() *Engine {
+	c := *e
+	return &c
+}
+
 func (e *Engine) newTx() (txEngine *Engine, err error) {
 
 	txEngine = e.clone()
 	db := e.getMaster()
 	if txEngine.tx, err = db.Begin(); err != nil {
-		log.Errorf("newTx error [%+v]", err.Error())
+		e.errorf("newTx error [%+v]", err.Error())
 		return nil, err
 	}
 	txEngine.operType = OperType_Tx
@@ -333,16 +370,16 @@ func (e *Engine) newTx() (txEngine *Engine, err error) {
 func (e *Engine) postgresQueryInsert(strSQL string) (lastInsertId int64, err error) {
 	var rows *sql.Rows
 	strSQL += fmt.Sprintf(" RETURNING \"%v\"", e.GetPkName())
-	log.Debugf("[%v]", strSQL)
+	e.debugf("[%v]", strSQL)
 	db := e.getMaster()
 	if rows, err = db.Query(strSQL); err != nil {
-		log.Errorf("tx.Query error [%v]", err.Error())
+		e.errorf("tx.Query error [%v]", err.Error())
 		return
 	}
 	defer rows.Close()
 	for rows.Next() {
 		if err = rows.Scan(&lastInsertId); err != nil {
-			log.Warnf("rows.Scan warning [%v]", err.Error())
+			e.warnf("rows.Scan warning [%v]", err.Error())
 			return
 		}
 	}
@@ -351,16 +388,16 @@ func (e *Engine) postgresQueryInsert(strSQL string) (lastInsertId int64, err err
 
 func (e *Engine) postgresQueryUpsert(strSQL string) (lastInsertId int64, err error) {
 	var rows *sql.Rows
-	log.Debugf("[%v]", strSQL)
+	e.debugf("[%v]", strSQL)
 	db := e.getMaster()
 	if rows, err = db.Query(strSQL); err != nil {
-		log.Errorf("tx.Query error [%v]", err.Error())
+		e.errorf("tx.Query error [%v]", err.Error())
 		return
 	}
 	defer rows.Close()
 	for rows.Next() {
 		if err = rows.Scan(&lastInsertId); err != nil {
-			log.Warnf("rows.Scan warning [%v]", err.Error())
+			e.warnf("rows.Scan warning [%v]", err.Error())
 			return
 		}
 	}
@@ -370,16 +407,16 @@ func (e *Engine) postgresQueryUpsert(strSQL string) (lastInsertId int64, err err
 func (e *Engine) mssqlQueryInsert(strSQL string) (lastInsertId int64, err error) {
 	var rows *sql.Rows
 	strSQL += " SELECT SCOPE_IDENTITY() AS last_insert_id"
-	log.Debugf("[%v]", strSQL)
+	e.debugf("[%v]", strSQL)
 	db := e.getMaster()
 	if rows, err = db.Query(strSQL); err != nil {
-		log.Errorf("tx.Query error [%v]", err.Error())
+		e.errorf("tx.Query error [%v]", err.Error())
 		return
 	}
 	defer rows.Close()
 	for rows.Next() {
 		if err = rows.Scan(&lastInsertId); err != nil {
-			log.Warnf("rows.Scan warning [%v]", err.Error())
+			e.warnf("rows.Scan warning [%v]", err.Error())
 			return
 		}
 	}
@@ -391,12 +428,13 @@ func (e *Engine) mssqlUpsert(strSQL string) (lastInsertId int64, err error) {
 	var db *Engine
 	var query = e.makeSqlxQueryPrimaryKey()
 	if db, err = e.TxBegin(); err != nil {
-		log.Errorf("TxBegin error [%v]", err.Error())
+		e.errorf("TxBegin error [%v]", err.Error())
 		return
 	}
+	db.interceptors = nil //statements of emulated upsert are intercepted once by Upsert
 	var count int64
 	if count, err = db.TxGet(&lastInsertId, query); err != nil {
-		log.Errorf("TxGet [%v] error [%v]", query, err.Error())
+		e.errorf("TxGet [%v] error [%v]", query, err.Error())
 		_ = db.TxRollback()
 		return
 	}
@@ -404,7 +442,7 @@ func (e *Engine) mssqlUpsert(strSQL string) (lastInsertId int64, err error) {
 		// INSERT INTO users(...) values(...)  SELECT SCOPE_IDENTITY() AS last_insert_id
 		//if _, _, err = db.TxExec(strSQL); err != nil
 		if lastInsertId, err = e.mssqlQueryInsert(strSQL); err != nil {
-			log.Errorf("mssqlQueryInsert [%v] error [%v]", strSQL, err.Error())
+			e.errorf("mssqlQueryInsert [%v] error [%v]", strSQL, err.Error())
 			_ = db.TxRollback()
 			return
 		}
@@ -414,16 +452,16 @@ func (e *Engine) mssqlUpsert(strSQL string) (lastInsertId int64, err error) {
 			DATABASE_KEY_NAME_UPDATE, e.getTableName(),
 			DATABASE_KEY_NAME_SET, e.getOnConflictDo(),
 			DATABASE_KEY_NAME_WHERE, e.GetPkName(), lastInsertId)
-		log.Debugf("%v", strUpdates)
+		e.debugf("%v", strUpdates)
 		if _, _, err = db.TxExec(strUpdates); err != nil {
-			log.Errorf("TxExec [%v] error [%v]", strSQL, err.Error())
+			e.errorf("TxExec [%v] error [%v]", strSQL, err.Error())
 			_ = db.TxRollback()
 			return
 		}
 	}
 
 	if err = db.TxCommit(); err != nil {
-		log.Errorf("TxCommit [%v] error [%v]", strSQL, err.Error())
+		e.errorf("TxCommit [%v] error [%v]", strSQL, err.Error())
 		return
 	}
 	return
@@ -475,9 +513,9 @@ func (e *Engine) setIndexes(name string, value interface{}) {
 	typ := reflect.TypeOf(value)
 	switch typ.Kind() {
 	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Func, reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
-		log.Errorf("index value type [%v] illegal", typ.Kind())
+		e.errorf("index value type [%v] illegal", typ.Kind())
 	}
-	e.cacheIndexes = append(e.cacheIndexes, tableIndex{
+	e.cacheIndexes = append(e.cacheIndexes[:len(e.cacheIndexes):len(e.cacheIndexes)], tableIndex{
 		Name:  name,
 		Value: value,
 	})
@@ -510,17 +548,17 @@ func (e *Engine) setPkValue(value interface{}) {
 	case string:
 		strValue = value.(string)
 		if strValue == "" {
-			log.Debugf("primary key's value is nil")
+			e.debugf("primary key's value is nil")
 		}
 	case int8, int16, int, int32, int64, uint8, uint16, uint, uint32, uint64:
 		{
 			strValue = fmt.Sprintf("%v", value)
 			if strValue == "0" {
-				log.Debugf("primary key's value is 0")
+				e.debugf("primary key's value is 0")
 			}
 		}
 	default:
-		log.Errorf("primary key's value type illegal")
+		e.errorf("primary key's value type illegal")
 	}
 	e.strPkValue = strValue
 }
@@ -594,7 +632,7 @@ func (e *Engine) getIndexWhere() (strCondition string) {
 func (e *Engine) getPkWhere() (strCondition string) {
 
 	if e.isPkValueNil() {
-		log.Debugf("query condition primary key or index is nil")
+		e.debugf("query condition primary key or index is nil")
 		return
 	}
 	strCondition = fmt.Sprintf("%v=%v",
@@ -770,6 +808,12 @@ func (e *Engine) isPkInteger() bool {
 
 func (e *Engine) isReadOnly(strIn string) bool {
 
+	if e.isSoftDeleteColumn(strIn) {
+		return true //soft delete column only written by Delete
+	}
+	if e.isTimestampColumn(strIn) {
+		return false //created/updated column filled by engine
+	}
 	if e.bForce {
 		return false
 	}
@@ -841,7 +885,7 @@ func (e *Engine) getQuoteUpdates(strColumns []string, strExcepts ...string) (str
 	var cols []string
 	for _, v := range strColumns {
 
-		if e.isColumnSelected(v, strExcepts...) && !e.isReadOnly(v) {
+		if e.isColumnSelected(v, strExcepts...) && !e.isReadOnly(v) && !e.isCreatedColumn(v) && !e.isVersionColumn(v) {
 			val := e.getModelValue(v)
 			if val == nil {
 				//log.Warnf("column [%v] selected but have no value", v)
@@ -938,8 +982,8 @@ func (e *Engine) getInsertColumnsAndValues() (strQuoteColumns, strColonValues st
 	} else {
 		for k, v := range e.dict {
 
-			if e.isReadOnly(k) {
-				continue
+			if v == nil || e.isReadOnly(k) {
+				continue //nil value means zero time, use database default value
 			}
 
 			c := fmt.Sprintf("%v%v%v", e.getForwardQuote(), k, e.getBackQuote()) // column name format to `id`,...
@@ -999,12 +1043,17 @@ func (e *Engine) makeSqlxString() (strSql string) {
 	case OperType_Upsert:
 		strSql = e.makeSqlxUpsert()
 	case OperType_Delete:
-		strSql = e.makeSqlxDelete()
+		if e.isSoftDelete() {
+			strSql = e.makeSqlxSoftDelete()
+		} else {
+			strSql = e.makeSqlxDelete()
+		}
+	case OperType_ForUpdate:
+		strSql = e.makeSqlxForUpdate()
 	default:
-		log.Errorf("operation illegal")
+		e.errorf("operation illegal")
 	}
 	strSql = strings.TrimSpace(strSql)
-	log.Debugf("[%v] SQL [%s]", e.operType, strSql)
 
 	return
 }
@@ -1047,7 +1096,7 @@ func (e *Engine) makeWhereCondition() (strWhere string) {
 			if e.operType != OperType_Update && e.operType != OperType_Delete {
 				strWhere += "1=1"
 			} else {
-				log.Warnf("where condition required when use orm update or delete")
+				e.warnf("where condition required when use orm update or delete")
 			}
 		} else {
 			strWhere += strCustomer
@@ -1066,6 +1115,15 @@ func (e *Engine) makeWhereCondition() (strWhere string) {
 	for _, v := range e.orConditions {
 		strWhere += fmt.Sprintf(" %v %v ", DATABASE_KEY_NAME_OR, v)
 	}
+	if e.isVersionLock() {
+		strWhere = fmt.Sprintf("(%v) %v %v", strings.TrimSpace(strWhere), DATABASE_KEY_NAME_AND, e.getVersionWhere())
+	}
+	if e.isSoftDelete() {
+		switch e.operType {
+		case OperType_Query, OperType_ForUpdate, OperType_Update, OperType_Delete:
+			strWhere = fmt.Sprintf("(%v) %v %v", strings.TrimSpace(strWhere), DATABASE_KEY_NAME_AND, e.getSoftDeleteWhere())
+		}
+	}
 	strWhere = DATABASE_KEY_NAME_WHERE + " " + strWhere
 	return
 }
@@ -1094,9 +1152,13 @@ func (e *Engine) makeSqlxForUpdate() (strSqlx string) {
 func (e *Engine) makeSqlxUpdate() (strSqlx string) {
 
 	strWhere := e.makeWhereCondition()
+	strUpdates := e.getQuoteUpdates(e.getSelectColumns(), e.GetPkName())
+	if e.isVersionLock() {
+		strUpdates += "," + e.getVersionUpdate()
+	}
 	strSqlx = fmt.Sprintf("%v %v %v %v %v %v",
 		DATABASE_KEY_NAME_UPDATE, e.getTableName(), DATABASE_KEY_NAME_SET,
-		e.getQuoteUpdates(e.getSelectColumns(), e.GetPkName()), strWhere, e.getLimit())
+		strUpdates, strWhere, e.getLimit())
 	assert(strSqlx, "update sql is nil")
 	return
 }
@@ -1133,15 +1195,11 @@ func (e *Engine) isQuestionPlaceHolder(query string, args ...interface{}) bool {
 	return false
 }
 
-func (e *Engine) cleanWhereCondition() {
-	e.strWhere = ""
-	e.strPkValue = ""
-	e.cacheIndexes = nil
-}
-
-func (e *Engine) autoRollback() {
-	if e.bAutoRollback && e.operType == OperType_Tx && e.tx != nil {
+// rollback tx when statement error occurred, AutoRollback must be set
+// if force is true (model hook returned error) tx is rolled back without AutoRollback
+func (e *Engine) autoRollback(force ...bool) {
+	if (e.bAutoRollback || (len(force) > 0 && force[0])) && e.operType == OperType_Tx && e.tx != nil {
 		_ = e.tx.Rollback()
-		log.Debugf("tx auto rollback successful")
+		e.debugf("tx auto rollback successful")
 	}
 }
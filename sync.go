@@ -0,0 +1,567 @@
+package sqlca
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	SQLCA_TAG_VALUE_PK       = "pk"      //primary key (default column is engine primary key name)
+	SQLCA_TAG_VALUE_TYPE     = "type"    //column type, eg. type=decimal(10,2)
+	SQLCA_TAG_VALUE_SIZE     = "size"    //string column size, eg. size=64
+	SQLCA_TAG_VALUE_NOT_NULL = "notnull" //column is NOT NULL (default of non-pointer field)
+	SQLCA_TAG_VALUE_NULL     = "null"    //column is NULL (default of pointer field)
+	SQLCA_TAG_VALUE_DEFAULT  = "default" //column default value, eg. default=0
+	SQLCA_TAG_VALUE_INDEX    = "index"   //index, eg. index or index=idx_name (same name means composite index)
+	SQLCA_TAG_VALUE_UNIQUE   = "unique"  //unique index, eg. unique or unique=uk_name
+)
+
+const (
+	SYNC_DEFAULT_STRING_SIZE = 255
+	SYNC_MODEL_NAME_SUFFIX   = "DO" //struct name suffix trimmed to table name, eg. UsersDO -> users
+)
+
+// model implements TableNamer to specify table name for Sync
+type TableNamer interface {
+	TableName() string
+}
+
+type syncColumn struct {
+	Name       string
+	Type       reflect.Type
+	Tags       map[string]string
+	IsPk       bool
+	IsAutoIncr bool
+	IsNullable bool
+}
+
+type syncIndex struct {
+	Name     string
+	Columns  []string
+	IsUnique bool
+}
+
+type syncTable struct {
+	Name    string
+	Columns []*syncColumn
+	Indexes []*syncIndex
+}
+
+// create tables or add missing columns/indexes from model structs (never drop or modify columns)
+// sqlca tag: pk, autoincr, type=varchar(64), size=64, notnull, null, default=0, index[=name], unique[=name]
+func (e *Engine) Sync(models ...interface{}) (statements []string, err error) {
+	return e.sync(false, models...)
+}
+
+// return DDL statements of Sync without executing
+func (e *Engine) SyncDryRun(models ...interface{}) (statements []string, err error) {
+	return e.sync(true, models...)
+}
+
+func (e *Engine) sync(dryRun bool, models ...interface{}) (statements []string, err error) {
+
+	for _, model := range models {
+		var table *syncTable
+		if table, err = e.parseSyncTable(model); err != nil {
+			return
+		}
+		var ddl []string
+		if ddl, err = e.makeSyncDDL(table); err != nil {
+			return
+		}
+		for _, v := range ddl {
+			if !dryRun {
+				e.infof("sync table [%v] execute [%v]", table.Name, v)
+				if _, _, err = e.ExecRaw(strings.Replace(v, "%", "%%", -1)); err != nil {
+					return
+				}
+			}
+			statements = append(statements, v)
+		}
+	}
+	return
+}
+
+func (e *Engine) parseSyncTable(model interface{}) (table *syncTable, err error) {
+
+	typ := reflect.TypeOf(model)
+	if typ == nil {
+		return nil, fmt.Errorf("sync model is nil")
+	}
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("sync model [%v] is not a struct", typ)
+	}
+	table = &syncTable{Name: getSyncTableName(model, typ)}
+	var dict = make(map[string]*syncIndex)
+	if err = e.parseSyncColumns(table, typ, dict); err != nil {
+		return
+	}
+	if len(table.Columns) == 0 {
+		return nil, fmt.Errorf("sync model [%v] has no column", typ)
+	}
+	for _, v := range dict {
+		table.Indexes = append(table.Indexes, v)
+	}
+	sort.Slice(table.Indexes, func(i, j int) bool { return table.Indexes[i].Name < table.Indexes[j].Name })
+	return
+}
+
+func (e *Engine) parseSyncColumns(table *syncTable, typ reflect.Type, indexes map[string]*syncIndex) (err error) {
+
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		strName := e.getTagValue(sf)
+		if sf.Anonymous && ft.Kind() == reflect.Struct && strName == "" {
+			if err = e.parseSyncColumns(table, ft, indexes); err != nil {
+				return
+			}
+			continue
+		}
+		if strName == "" || strName == SQLCA_TAG_VALUE_IGNORE || sf.PkgPath != "" {
+			continue
+		}
+		tags := getSqlcaTagOptions(sf)
+		col := &syncColumn{
+			Name:       strName,
+			Type:       ft,
+			Tags:       tags,
+			IsNullable: sf.Type.Kind() == reflect.Ptr,
+		}
+		_, col.IsPk = tags[SQLCA_TAG_VALUE_PK]
+		if strName == e.GetPkName() {
+			col.IsPk = true
+		}
+		if _, ok := tags[SQLCA_TAG_VALUE_NULL]; ok {
+			col.IsNullable = true
+		}
+		if _, ok := tags[SQLCA_TAG_VALUE_NOT_NULL]; ok || col.IsPk {
+			col.IsNullable = false
+		}
+		_, col.IsAutoIncr = tags[SQLCA_TAG_VALUE_AUTO_INCR]
+		if col.IsPk && isSyncIntegerKind(ft.Kind()) {
+			col.IsAutoIncr = true
+		}
+		for _, strKey := range []string{SQLCA_TAG_VALUE_INDEX, SQLCA_TAG_VALUE_UNIQUE} {
+			strIndex, ok := tags[strKey]
+			if !ok {
+				continue
+			}
+			bUnique := strKey == SQLCA_TAG_VALUE_UNIQUE
+			if strIndex == "" {
+				strPrefix := "idx"
+				if bUnique {
+					strPrefix = "uk"
+				}
+				strIndex = fmt.Sprintf("%v_%v_%v", strPrefix, table.Name, strName)
+			}
+			idx, ok := indexes[strIndex]
+			if !ok {
+				idx = &syncIndex{Name: strIndex, IsUnique: bUnique}
+				indexes[strIndex] = idx
+			} else if idx.IsUnique != bUnique {
+				return fmt.Errorf("table [%v] index [%v] is both unique and non-unique", table.Name, strIndex)
+			}
+			idx.Columns = append(idx.Columns, strName)
+		}
+		table.Columns = append(table.Columns, col)
+	}
+	return
+}
+
+// DDL of create table or alter table add column/index
+func (e *Engine) makeSyncDDL(table *syncTable) (ddl []string, err error) {
+
+	var columns, indexes []string
+	if columns, err = e.querySyncColumns(table.Name); err != nil {
+		return
+	}
+	strTable := e.getQuoteColumnName(table.Name)
+	if len(columns) == 0 {
+		var defs []string
+		var pks []string
+		for _, col := range table.Columns {
+			defs = append(defs, e.getSyncColumnDefine(col))
+			if col.IsPk && !(col.IsAutoIncr && e.adapterSqlx == AdapterSqlx_Sqlite) {
+				pks = append(pks, e.getQuoteColumnName(col.Name))
+			}
+		}
+		if len(pks) > 0 {
+			defs = append(defs, fmt.Sprintf("PRIMARY KEY (%v)", strings.Join(pks, ",")))
+		}
+		ddl = append(ddl, fmt.Sprintf("CREATE TABLE %v (%v)", strTable, strings.Join(defs, ", ")))
+	} else {
+		if indexes, err = e.querySyncIndexes(table.Name); err != nil {
+			return
+		}
+		strAdd := "ADD COLUMN"
+		if e.adapterSqlx == AdapterSqlx_Mssql {
+			strAdd = "ADD"
+		}
+		for _, col := range table.Columns {
+			if containsFold(columns, col.Name) {
+				continue
+			}
+			if col.IsPk {
+				return nil, fmt.Errorf("table [%v] primary key column [%v] can not be added", table.Name, col.Name)
+			}
+			if _, ok := col.Tags[SQLCA_TAG_VALUE_DEFAULT]; !ok && !col.IsNullable {
+				if strZero := getSyncZeroValue(col.Type); strZero != "" {
+					col.Tags[SQLCA_TAG_VALUE_DEFAULT] = strZero //existing rows need a default value
+				}
+			}
+			ddl = append(ddl, fmt.Sprintf("ALTER TABLE %v %v %v", strTable, strAdd, e.getSyncColumnDefine(col)))
+		}
+	}
+	for _, idx := range table.Indexes {
+		if containsFold(indexes, idx.Name) {
+			continue
+		}
+		var cols []string
+		for _, v := range idx.Columns {
+			cols = append(cols, e.getQuoteColumnName(v))
+		}
+		strUnique := ""
+		if idx.IsUnique {
+			strUnique = "UNIQUE "
+		}
+		ddl = append(ddl, fmt.Sprintf("CREATE %vINDEX %v ON %v (%v)", strUnique, e.getQuoteColumnName(idx.Name), strTable, strings.Join(cols, ",")))
+	}
+	return
+}
+
+// column name, type, auto increment, nullable and default
+func (e *Engine) getSyncColumnDefine(col *syncColumn) string {
+
+	strDefine := e.getQuoteColumnName(col.Name) + " " + e.getSyncColumnType(col)
+	if col.IsAutoIncr {
+		switch e.adapterSqlx {
+		case AdapterSqlx_MySQL:
+			return strDefine + " NOT NULL AUTO_INCREMENT"
+		case AdapterSqlx_Mssql:
+			return strDefine + " IDENTITY(1,1) NOT NULL"
+		case AdapterSqlx_Sqlite:
+			return strDefine + " PRIMARY KEY AUTOINCREMENT"
+		case AdapterSqlx_Postgres:
+			return strDefine //serial is not null
+		}
+	}
+	if col.IsNullable {
+		strDefine += " NULL"
+	} else {
+		strDefine += " NOT NULL"
+	}
+	if strDefault, ok := col.Tags[SQLCA_TAG_VALUE_DEFAULT]; ok {
+		strDefine += " DEFAULT " + getSyncDefaultValue(strDefault)
+	}
+	return strDefine
+}
+
+// column type by tag or go type of adapter
+func (e *Engine) getSyncColumnType(col *syncColumn) string {
+
+	if strType, ok := col.Tags[SQLCA_TAG_VALUE_TYPE]; ok && strType != "" {
+		return strType
+	}
+	adapter := e.adapterSqlx
+	kind := col.Type.Kind()
+	if col.IsAutoIncr {
+		switch adapter {
+		case AdapterSqlx_Sqlite:
+			return "INTEGER" //must be INTEGER for AUTOINCREMENT
+		case AdapterSqlx_Postgres:
+			if kind == reflect.Int64 || kind == reflect.Uint64 || kind == reflect.Uint32 {
+				return "BIGSERIAL"
+			}
+			return "SERIAL"
+		}
+	}
+	var strUnsigned string
+	if adapter == AdapterSqlx_MySQL {
+		strUnsigned = " UNSIGNED"
+	}
+	switch col.Type {
+	case typeOfTime:
+		switch adapter {
+		case AdapterSqlx_Postgres:
+			return "TIMESTAMP"
+		case AdapterSqlx_Mssql:
+			return "DATETIME2"
+		}
+		return "DATETIME"
+	case reflect.TypeOf(Decimal{}):
+		return "DECIMAL(32,8)"
+	}
+	switch kind {
+	case reflect.Bool:
+		switch adapter {
+		case AdapterSqlx_MySQL:
+			return "TINYINT(1)"
+		case AdapterSqlx_Postgres:
+			return "BOOLEAN"
+		case AdapterSqlx_Mssql:
+			return "BIT"
+		}
+		return "INTEGER"
+	case reflect.Int8:
+		if adapter == AdapterSqlx_MySQL {
+			return "TINYINT"
+		}
+		return "SMALLINT"
+	case reflect.Uint8:
+		if adapter == AdapterSqlx_MySQL {
+			return "TINYINT" + strUnsigned
+		}
+		return "SMALLINT"
+	case reflect.Int16:
+		return "SMALLINT"
+	case reflect.Uint16:
+		if adapter == AdapterSqlx_MySQL {
+			return "SMALLINT" + strUnsigned
+		}
+		return "INTEGER"
+	case reflect.Int32, reflect.Int:
+		if adapter == AdapterSqlx_MySQL || adapter == AdapterSqlx_Mssql {
+			return "INT"
+		}
+		return "INTEGER"
+	case reflect.Uint32, reflect.Uint:
+		if adapter == AdapterSqlx_MySQL {
+			return "INT" + strUnsigned
+		}
+		return "BIGINT"
+	case reflect.Int64:
+		return "BIGINT"
+	case reflect.Uint64:
+		if adapter == AdapterSqlx_MySQL {
+			return "BIGINT" + strUnsigned
+		}
+		return "NUMERIC(20)"
+	case reflect.Float32:
+		if adapter == AdapterSqlx_MySQL {
+			return "FLOAT"
+		}
+		return "REAL"
+	case reflect.Float64:
+		switch adapter {
+		case AdapterSqlx_MySQL:
+			return "DOUBLE"
+		case AdapterSqlx_Postgres:
+			return "DOUBLE PRECISION"
+		case AdapterSqlx_Mssql:
+			return "FLOAT"
+		}
+		return "REAL"
+	case reflect.String:
+		size := SYNC_DEFAULT_STRING_SIZE
+		if strSize, ok := col.Tags[SQLCA_TAG_VALUE_SIZE]; ok {
+			if n, err := strconv.Atoi(strSize); err == nil && n > 0 {
+				size = n
+			}
+		}
+		switch adapter {
+		case AdapterSqlx_Sqlite:
+			return "TEXT"
+		case AdapterSqlx_Mssql:
+			if size > 4000 {
+				return "NVARCHAR(MAX)"
+			}
+			return fmt.Sprintf("NVARCHAR(%v)", size)
+		case AdapterSqlx_MySQL:
+			if size > 65535 {
+				return "LONGTEXT"
+			}
+		}
+		return fmt.Sprintf("VARCHAR(%v)", size)
+	case reflect.Slice:
+		if col.Type.Elem().Kind() == reflect.Uint8 {
+			switch adapter {
+			case AdapterSqlx_Postgres:
+				return "BYTEA"
+			case AdapterSqlx_Mssql:
+				return "VARBINARY(MAX)"
+			}
+			return "BLOB"
+		}
+	}
+	//struct/map/slice stored as text (eg. json)
+	if adapter == AdapterSqlx_Mssql {
+		return "NVARCHAR(MAX)"
+	}
+	return "TEXT"
+}
+
+// existing column names of table, empty if table not exist
+func (e *Engine) querySyncColumns(strTable string) (columns []string, err error) {
+
+	var strQuery string
+	strName := strings.Replace(strTable, "'", "''", -1)
+	switch e.adapterSqlx {
+	case AdapterSqlx_MySQL:
+		strQuery = fmt.Sprintf("SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA=DATABASE() AND TABLE_NAME='%v'", strName)
+	case AdapterSqlx_Postgres:
+		strQuery = fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_schema=current_schema() AND table_name='%v'", strName)
+	case AdapterSqlx_Mssql:
+		strQuery = fmt.Sprintf("SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_NAME='%v'", strName)
+	case AdapterSqlx_Sqlite:
+		strQuery = fmt.Sprintf("SELECT name FROM pragma_table_info('%v')", strName)
+	default:
+		return nil, fmt.Errorf("adapter [%v] not support sync", e.adapterSqlx)
+	}
+	_, err = e.Model(&columns).QueryRaw(strings.Replace(strQuery, "%", "%%", -1))
+	return
+}
+
+// existing index names of table
+func (e *Engine) querySyncIndexes(strTable string) (indexes []string, err error) {
+
+	var strQuery string
+	strName := strings.Replace(strTable, "'", "''", -1)
+	switch e.adapterSqlx {
+	case AdapterSqlx_MySQL:
+		strQuery = fmt.Sprintf("SELECT DISTINCT INDEX_NAME FROM INFORMATION_SCHEMA.STATISTICS WHERE TABLE_SCHEMA=DATABASE() AND TABLE_NAME='%v'", strName)
+	case AdapterSqlx_Postgres:
+		strQuery = fmt.Sprintf("SELECT indexname FROM pg_indexes WHERE schemaname=current_schema() AND tablename='%v'", strName)
+	case AdapterSqlx_Mssql:
+		strQuery = fmt.Sprintf("SELECT name FROM sys.indexes WHERE object_id=OBJECT_ID('%v') AND name IS NOT NULL", strName)
+	case AdapterSqlx_Sqlite:
+		strQuery = fmt.Sprintf("SELECT name FROM pragma_index_list('%v')", strName)
+	}
+	_, err = e.Model(&indexes).QueryRaw(strings.Replace(strQuery, "%", "%%", -1))
+	return
+}
+
+// table name from TableName() or snake case of struct name without suffix 'DO' (eg. UserRolesDO -> user_roles)
+func getSyncTableName(model interface{}, typ reflect.Type) string {
+
+	if v, ok := model.(TableNamer); ok {
+		return v.TableName()
+	}
+	if v, ok := reflect.New(typ).Interface().(TableNamer); ok {
+		return v.TableName()
+	}
+	strName := typ.Name()
+	if len(strName) > len(SYNC_MODEL_NAME_SUFFIX) {
+		strName = strings.TrimSuffix(strName, SYNC_MODEL_NAME_SUFFIX)
+	}
+	return toSnakeCase(strName)
+}
+
+func toSnakeCase(strIn string) string {
+
+	var sb strings.Builder
+	runes := []rune(strIn)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// quote string default value, numbers/keywords/functions/quoted values are kept
+func getSyncDefaultValue(strValue string) string {
+
+	if strValue == "" {
+		return "''"
+	}
+	if strings.HasPrefix(strValue, "'") || strings.Contains(strValue, "(") {
+		return strValue
+	}
+	if _, err := strconv.ParseFloat(strValue, 64); err == nil {
+		return strValue
+	}
+	if strValue == strings.ToUpper(strValue) {
+		return strValue //eg. CURRENT_TIMESTAMP, NULL, TRUE
+	}
+	return "'" + strings.Replace(strValue, "'", "''", -1) + "'"
+}
+
+// zero value of go type as column default, empty if unknown (eg. time)
+func getSyncZeroValue(typ reflect.Type) string {
+	switch typ.Kind() {
+	case reflect.String:
+		return "''"
+	case reflect.Bool, reflect.Float32, reflect.Float64:
+		return "0"
+	}
+	if isSyncIntegerKind(typ.Kind()) || typ == reflect.TypeOf(Decimal{}) {
+		return "0"
+	}
+	return ""
+}
+
+func isSyncIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func containsFold(values []string, strValue string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, strValue) {
+			return true
+		}
+	}
+	return false
+}
+
+// split sqlca tag by ',' out of brackets into key/value options (eg. type=decimal(10,2),notnull)
+func getSqlcaTagOptions(sf reflect.StructField) (options map[string]string) {
+
+	options = make(map[string]string)
+	for _, v := range splitSqlcaTag(sf.Tag.Get(TAG_NAME_SQLCA)) {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) == 2 {
+			options[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		} else {
+			options[v] = ""
+		}
+	}
+	return
+}
+
+func splitSqlcaTag(strTag string) (values []string) {
+
+	var depth, start int
+	for i := 0; i <= len(strTag); i++ {
+		if i < len(strTag) {
+			switch strTag[i] {
+			case '(':
+				depth++
+				continue
+			case ')':
+				depth--
+				continue
+			case ',':
+				if depth > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		if s := strings.TrimSpace(strTag[start:i]); s != "" {
+			values = append(values, s)
+		}
+		start = i + 1
+	}
+	return
+}
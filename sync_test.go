@@ -0,0 +1,153 @@
+package sqlca
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type SyncClassesDO struct {
+	Id        int64      `db:"id"`
+	Name      string     `db:"name" sqlca:"size=64,default='',index=idx_class_name"`
+	Phone     string     `db:"phone" sqlca:"size=16,unique"`
+	Balance   float64    `db:"balance" sqlca:"type=decimal(10,2),default=0"`
+	ClassNo   string     `db:"class_no" sqlca:"index=idx_class_name"`
+	DeletedAt *time.Time `db:"deleted_at" sqlca:"softdelete"`
+}
+
+// the same table with new columns and index
+type syncClassesV2 struct {
+	SyncClassesDO
+	Grade   int32  `db:"grade" sqlca:"index"`
+	Remark  string `db:"remark" sqlca:"null"`
+	private int
+}
+
+func (syncClassesV2) TableName() string {
+	return "sync_classes"
+}
+
+func TestSyncSqlite(t *testing.T) {
+
+	e, done := newTestEngine(t)
+	defer done()
+
+	statements, err := e.SyncDryRun(&SyncClassesDO{})
+	if err != nil {
+		t.Fatalf("sync dry run error (%v)", err)
+	}
+	expect := []string{
+		"CREATE TABLE `sync_classes` (`id` INTEGER PRIMARY KEY AUTOINCREMENT, `name` TEXT NOT NULL DEFAULT '', `phone` TEXT NOT NULL, " +
+			"`balance` decimal(10,2) NOT NULL DEFAULT 0, `class_no` TEXT NOT NULL, `deleted_at` DATETIME NULL)",
+		"CREATE INDEX `idx_class_name` ON `sync_classes` (`name`,`class_no`)",
+		"CREATE UNIQUE INDEX `uk_sync_classes_phone` ON `sync_classes` (`phone`)",
+	}
+	if !reflect.DeepEqual(statements, expect) {
+		t.Fatalf("\n%v\nexpected, got\n%v", strings.Join(expect, "\n"), strings.Join(statements, "\n"))
+	}
+	if columns, _ := e.querySyncColumns("sync_classes"); len(columns) != 0 {
+		t.Fatalf("dry run must not create table, got columns %v", columns)
+	}
+
+	if _, err = e.Sync(&SyncClassesDO{}); err != nil {
+		t.Fatalf("sync error (%v)", err)
+	}
+	if statements, err = e.Sync(&SyncClassesDO{}); err != nil || len(statements) != 0 {
+		t.Errorf("nothing to sync expected, got %v error %v", statements, err)
+	}
+
+	//additive changes only
+	if statements, err = e.Sync(&syncClassesV2{}); err != nil {
+		t.Fatalf("sync v2 error (%v)", err)
+	}
+	expect = []string{
+		"ALTER TABLE `sync_classes` ADD COLUMN `grade` INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE `sync_classes` ADD COLUMN `remark` TEXT NULL",
+		"CREATE INDEX `idx_sync_classes_grade` ON `sync_classes` (`grade`)",
+	}
+	if !reflect.DeepEqual(statements, expect) {
+		t.Fatalf("\n%v\nexpected, got\n%v", strings.Join(expect, "\n"), strings.Join(statements, "\n"))
+	}
+	if columns, _ := e.querySyncColumns("sync_classes"); len(columns) != 8 {
+		t.Errorf("8 columns expected, got %v", columns)
+	}
+}
+
+func TestSyncModelError(t *testing.T) {
+
+	e, done := newTestEngine(t)
+	defer done()
+
+	type noColumn struct {
+		Name string
+	}
+	type mixedIndex struct {
+		Id int64  `db:"id"`
+		A  string `db:"a" sqlca:"index=idx_a"`
+		B  string `db:"b" sqlca:"unique=idx_a"`
+	}
+	for _, v := range []interface{}{nil, 1, &noColumn{}, &mixedIndex{}} {
+		if _, err := e.SyncDryRun(v); err == nil {
+			t.Errorf("model %T must fail", v)
+		}
+	}
+}
+
+func TestSyncColumnType(t *testing.T) {
+
+	type model struct {
+		Id      int32     `db:"id"`
+		Flag    bool      `db:"flag"`
+		Amount  uint64    `db:"amount"`
+		Price   Decimal   `db:"price"`
+		Created time.Time `db:"created"`
+		Data    []byte    `db:"data"`
+		Note    string    `db:"note" sqlca:"size=5000"`
+	}
+	var cases = []struct {
+		adapter AdapterType
+		expect  []string
+	}{
+		{AdapterSqlx_MySQL, []string{"INT", "TINYINT(1)", "BIGINT UNSIGNED", "DECIMAL(32,8)", "DATETIME", "BLOB", "VARCHAR(5000)"}},
+		{AdapterSqlx_Postgres, []string{"SERIAL", "BOOLEAN", "NUMERIC(20)", "DECIMAL(32,8)", "TIMESTAMP", "BYTEA", "VARCHAR(5000)"}},
+		{AdapterSqlx_Mssql, []string{"INT", "BIT", "NUMERIC(20)", "DECIMAL(32,8)", "DATETIME2", "VARBINARY(MAX)", "NVARCHAR(MAX)"}},
+		{AdapterSqlx_Sqlite, []string{"INTEGER", "INTEGER", "NUMERIC(20)", "DECIMAL(32,8)", "DATETIME", "BLOB", "TEXT"}},
+	}
+	for _, v := range cases {
+		e := NewEngine()
+		e.SetLogger(NewNopLogger())
+		e.adapterSqlx = v.adapter
+		table, err := e.parseSyncTable(&model{})
+		if err != nil {
+			t.Fatalf("parse model error (%v)", err)
+		}
+		var types []string
+		for _, col := range table.Columns {
+			types = append(types, e.getSyncColumnType(col))
+		}
+		if !reflect.DeepEqual(types, v.expect) {
+			t.Errorf("adapter [%v]: %v expected, got %v", v.adapter, v.expect, types)
+		}
+	}
+}
+
+func TestSyncHelpers(t *testing.T) {
+
+	for k, v := range map[string]string{"UserRoles": "user_roles", "HTTPServer": "http_server", "userID": "user_id", "users": "users"} {
+		if s := toSnakeCase(k); s != v {
+			t.Errorf("snake case of %v: %v expected, got %v", k, v, s)
+		}
+	}
+	for k, v := range map[string]string{"": "''", "0": "0", "1.5": "1.5", "CURRENT_TIMESTAMP": "CURRENT_TIMESTAMP", "now()": "now()", "'x'": "'x'", "it's": "'it''s'"} {
+		if s := getSyncDefaultValue(k); s != v {
+			t.Errorf("default value of %q: %v expected, got %v", k, v, s)
+		}
+	}
+	if s := splitSqlcaTag("type=decimal(10,2), notnull,index=idx_a"); !reflect.DeepEqual(s, []string{"type=decimal(10,2)", "notnull", "index=idx_a"}) {
+		t.Errorf("split sqlca tag got %q", s)
+	}
+	if s := getSyncTableName(&SyncClassesDO{}, reflect.TypeOf(SyncClassesDO{})); s != "sync_classes" {
+		t.Errorf("table name sync_classes expected, got %v", s)
+	}
+}
@@ -0,0 +1,242 @@
+package sqlca
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	DATETIME_FORMAT = "2006-01-02 15:04:05"
+)
+
+// layouts to parse datetime string from database into time.Time
+var timeLayouts = []string{
+	DATETIME_FORMAT,
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02",
+}
+
+var typeOfTime = reflect.TypeOf(time.Time{})
+
+// timestamp column parsed from struct tag sqlca:"created" or sqlca:"updated"
+type timestampColumn struct {
+	Name     string // column name
+	bCreated bool   // true=created false=updated
+}
+
+// find fields which sqlca tag contains option from model type (recurse embedded struct)
+func (e *Engine) getSqlcaTagFields(typ reflect.Type, strOpt string) (fields []reflect.StructField) {
+
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if hasSqlcaTagValue(sf, strOpt) {
+			fields = append(fields, sf)
+			continue
+		}
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && ft.Kind() == reflect.Struct {
+			fields = append(fields, e.getSqlcaTagFields(ft, strOpt)...)
+		}
+	}
+	return
+}
+
+func (e *Engine) setTimestamps(model interface{}) {
+	if model == nil {
+		return
+	}
+	var columns []timestampColumn
+	typ := reflect.TypeOf(model)
+	for _, sf := range e.getSqlcaTagFields(typ, SQLCA_TAG_VALUE_CREATED) {
+		if strName := e.getTagValue(sf); strName != "" && e.checkTimestampField(sf, strName) {
+			columns = append(columns, timestampColumn{Name: strName, bCreated: true})
+		}
+	}
+	for _, sf := range e.getSqlcaTagFields(typ, SQLCA_TAG_VALUE_UPDATED) {
+		if strName := e.getTagValue(sf); strName != "" && e.checkTimestampField(sf, strName) {
+			columns = append(columns, timestampColumn{Name: strName})
+		}
+	}
+	if len(columns) > 0 {
+		e.timestamps = columns
+	}
+}
+
+// timestamp field must be time.Time, string or a number can hold unix seconds (int8/int16/int32/float32 overflow or lose seconds)
+func (e *Engine) checkTimestampField(sf reflect.StructField, strColumn string) bool {
+	ft := sf.Type
+	if ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+	switch {
+	case ft == typeOfTime, ft.Kind() == reflect.String:
+		return true
+	case ft.Kind() >= reflect.Int && ft.Kind() <= reflect.Int64, ft.Kind() >= reflect.Uint && ft.Kind() <= reflect.Uint64:
+		if ft.Bits() >= 64 {
+			return true
+		}
+	case ft.Kind() == reflect.Float64:
+		return true
+	}
+	e.warnf("timestamp column [%v] type [%v] not support, use time.Time, string, int64 or uint64 instead", strColumn, ft)
+	return false
+}
+
+func (e *Engine) isTimestampColumn(strColumn string) bool {
+	for _, v := range e.timestamps {
+		if v.Name == strColumn {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Engine) isCreatedColumn(strColumn string) bool {
+	for _, v := range e.timestamps {
+		if v.Name == strColumn && v.bCreated {
+			return true
+		}
+	}
+	return false
+}
+
+// fill created/updated columns of model and dictionary before insert/upsert/update
+// created columns are only filled by insert/upsert
+func (e *Engine) fillTimestamps() {
+
+	if len(e.timestamps) == 0 {
+		return
+	}
+	now := time.Now()
+	dict := make(map[string]interface{}, len(e.dict)+len(e.timestamps))
+	for k, v := range e.dict {
+		dict[k] = v //dictionary may be shared by builders
+	}
+	for _, v := range e.timestamps {
+		if v.bCreated && e.operType == OperType_Update {
+			continue
+		}
+		if value := e.setModelTime(v.Name, now); value != nil {
+			dict[v.Name] = value
+		}
+		if !v.bCreated && !e.isColumnSelected(v.Name) {
+			e.selectColumns = append(e.selectColumns[:len(e.selectColumns):len(e.selectColumns)], v.Name)
+		}
+	}
+	e.dict = dict
+}
+
+// write time to model field(s) of column, returns the value to be saved to database
+func (e *Engine) setModelTime(strColumn string, now time.Time) (value interface{}) {
+
+	val := reflect.ValueOf(e.model)
+	for val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	switch val.Kind() {
+	case reflect.Struct:
+		value = e.setStructTime(val, strColumn, now)
+	case reflect.Slice:
+		for i := 0; i < val.Len(); i++ {
+			elem := val.Index(i)
+			for elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+			if elem.Kind() == reflect.Struct {
+				value = e.setStructTime(elem, strColumn, now)
+			}
+		}
+	}
+	return
+}
+
+func (e *Engine) setStructTime(val reflect.Value, strColumn string, now time.Time) (value interface{}) {
+
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		fv := val.Field(i)
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && ft.Kind() == reflect.Struct && ft != typeOfTime {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if v := e.setStructTime(fv, strColumn, now); v != nil {
+				value = v
+			}
+			continue
+		}
+		if e.getTagValue(sf) != strColumn || !fv.CanSet() {
+			continue
+		}
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				fv.Set(reflect.New(ft))
+			}
+			fv = fv.Elem()
+		}
+		switch {
+		case ft == typeOfTime:
+			fv.Set(reflect.ValueOf(now))
+			value = now.Format(DATETIME_FORMAT)
+		case ft.Kind() == reflect.String:
+			fv.SetString(now.Format(DATETIME_FORMAT))
+			value = fv.String()
+		case ft.Kind() >= reflect.Int && ft.Kind() <= reflect.Int64:
+			fv.SetInt(now.Unix())
+			value = now.Unix()
+		case ft.Kind() >= reflect.Uint && ft.Kind() <= reflect.Uint64:
+			fv.SetUint(uint64(now.Unix()))
+			value = now.Unix()
+		case ft.Kind() == reflect.Float64:
+			fv.SetFloat(float64(now.Unix()))
+			value = now.Unix()
+		default:
+			e.warnf("timestamp column [%v] type [%v] not support", strColumn, ft)
+		}
+	}
+	return
+}
+
+// format time.Time to datetime string, zero time returns nil (use database default value)
+func fmtTimeValue(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Format(DATETIME_FORMAT)
+}
+
+// parse datetime string from database
+func parseTimeValue(strValue string) (t time.Time, ok bool) {
+	strValue = strings.TrimSpace(strValue)
+	if strValue == "" {
+		return
+	}
+	for _, layout := range timeLayouts {
+		var err error
+		if t, err = time.ParseInLocation(layout, strValue, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return
+}
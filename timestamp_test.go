@@ -0,0 +1,79 @@
+package sqlca
+
+import (
+	"testing"
+	"time"
+)
+
+const testArticlesDDL = "CREATE TABLE articles (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT NOT NULL DEFAULT '', " +
+	"created_at TEXT, updated_at INTEGER NOT NULL DEFAULT 0)"
+
+type timestampArticle struct {
+	Id        int64     `db:"id"`
+	Title     string    `db:"title"`
+	CreatedAt time.Time `db:"created_at" sqlca:"created"`
+	UpdatedAt int64     `db:"updated_at" sqlca:"updated"`
+}
+
+type narrowTimestampArticle struct {
+	Id        int64   `db:"id"`
+	Title     string  `db:"title"`
+	CreatedAt int32   `db:"created_at" sqlca:"created"`
+	UpdatedAt float32 `db:"updated_at" sqlca:"updated"`
+}
+
+func TestTimestamps(t *testing.T) {
+
+	e, done := newTestEngine(t, testArticlesDDL)
+	defer done()
+
+	now := time.Now().Unix()
+	a := timestampArticle{Title: "a"}
+	id, err := e.Model(&a).Table("articles").Insert()
+	if err != nil {
+		t.Fatalf("insert error (%v)", err)
+	}
+	if a.CreatedAt.Unix() < now || a.UpdatedAt < now {
+		t.Errorf("timestamps must be written back to model, got %+v", a)
+	}
+	var saved timestampArticle
+	if _, err = e.Model(&saved).Table("articles").Id(id).Query(); err != nil {
+		t.Fatalf("query error (%v)", err)
+	}
+	if saved.CreatedAt.Unix() != a.CreatedAt.Unix() || saved.UpdatedAt != a.UpdatedAt {
+		t.Errorf("saved timestamps %+v expected, got %+v", a, saved)
+	}
+
+	//update fills updated column only, even it's not selected
+	a = timestampArticle{Id: id, Title: "b"}
+	if _, err = e.Model(&a).Table("articles").Select("title").Update(); err != nil {
+		t.Fatalf("update error (%v)", err)
+	}
+	if !a.CreatedAt.IsZero() || a.UpdatedAt < now {
+		t.Errorf("only updated column must be filled by update, got %+v", a)
+	}
+	if _, err = e.Model(&saved).Table("articles").Id(id).Query(); err != nil {
+		t.Fatalf("query error (%v)", err)
+	}
+	if saved.Title != "b" || saved.UpdatedAt != a.UpdatedAt || saved.CreatedAt.IsZero() {
+		t.Errorf("created kept and updated saved expected, got %+v", saved)
+	}
+}
+
+func TestTimestampsNarrowType(t *testing.T) {
+
+	e, done := newTestEngine(t, testArticlesDDL)
+	defer done()
+
+	a := narrowTimestampArticle{Title: "a"}
+	c := e.Model(&a).Table("articles")
+	if len(c.timestamps) != 0 {
+		t.Errorf("int32/float32 timestamp fields must be ignored, got %+v", c.timestamps)
+	}
+	if _, err := c.Insert(); err != nil {
+		t.Fatalf("insert error (%v)", err)
+	}
+	if a.CreatedAt != 0 || a.UpdatedAt != 0 {
+		t.Errorf("narrow fields must not be filled, got %+v", a)
+	}
+}
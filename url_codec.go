@@ -3,7 +3,6 @@ package sqlca
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/civet148/gotools/log"
 	"github.com/civet148/redigogo"
 	"net/url"
 	"strconv"
@@ -170,7 +169,7 @@ func ParseUrl(strUrl string) (ui *UrlInfo) {
 func parseDatabaseName(strPath string) string {
 	idx := strings.LastIndex(strPath, "/")
 	if idx == -1 {
-		log.Errorf("[%v] invalid database path", strPath)
+		defaultLogger.Error(fmt.Sprintf("[%v] invalid database path", strPath))
 	}
 	return strPath[idx+1:]
 }
@@ -303,7 +302,7 @@ func (e *Engine) parseRedisUrl(strUrl string) (parameter dsnParameter) {
 	}
 
 	if jsonData, err := json.Marshal(cc); err != nil {
-		log.Errorf("url [%v] illegal", strUrl)
+		e.errorf("url [%v] illegal", strUrl)
 	} else {
 		parameter.strDSN = string(jsonData)
 	}
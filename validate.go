@@ -0,0 +1,199 @@
+package sqlca
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	TAG_NAME_VALIDATE = "validate"
+)
+
+const (
+	VALIDATE_RULE_REQUIRED = "required" //value must not be zero (nil pointer, empty string, 0...)
+	VALIDATE_RULE_MIN      = "min"      //min=N string length or number value
+	VALIDATE_RULE_MAX      = "max"      //max=N string length or number value
+	VALIDATE_RULE_ONEOF    = "oneof"    //oneof=a b c
+	VALIDATE_RULE_EMAIL    = "email"    //email format
+	VALIDATE_RULE_REGEX    = "regex"    //regex=^[0-9]+$ (must be the last rule, pattern may contains comma)
+)
+
+var regexEmail = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+
+var regexCache sync.Map //pattern -> *regexp.Regexp
+
+// field validation error
+type FieldError struct {
+	Field   string      // struct field name
+	Column  string      // column name
+	Rule    string      // rule failed (eg. max=32)
+	Value   interface{} // field value
+	Message string      // error message
+}
+
+func (fe *FieldError) Error() string {
+	return fmt.Sprintf("column [%v] %v", fe.Column, fe.Message)
+}
+
+// aggregated validation errors returned by Insert/Upsert/Update
+type ValidationErrors []*FieldError
+
+func (ve ValidationErrors) Error() string {
+	var ss []string
+	for _, v := range ve {
+		ss = append(ss, v.Error())
+	}
+	return "validation failed: " + strings.Join(ss, "; ")
+}
+
+type validateRule struct {
+	Name  string
+	Param string
+}
+
+// parse tag like validate:"required,max=32,regex=^[a-z,]+$"
+func parseValidateRules(strTag string) (rules []validateRule) {
+
+	for strTag != "" {
+		var strRule string
+		if strings.HasPrefix(strTag, VALIDATE_RULE_REGEX+"=") {
+			strRule, strTag = strTag, "" //regex pattern is the rest of tag
+		} else if idx := strings.Index(strTag, ","); idx != -1 {
+			strRule, strTag = strTag[:idx], strTag[idx+1:]
+		} else {
+			strRule, strTag = strTag, ""
+		}
+		strRule = strings.TrimSpace(strRule)
+		if strRule == "" {
+			continue
+		}
+		var rule validateRule
+		if idx := strings.Index(strRule, "="); idx != -1 {
+			rule.Name, rule.Param = strRule[:idx], strRule[idx+1:]
+		} else {
+			rule.Name = strRule
+		}
+		rules = append(rules, rule)
+	}
+	return
+}
+
+func getValidateRegex(strPattern string) (*regexp.Regexp, error) {
+	if v, ok := regexCache.Load(strPattern); ok {
+		return v.(*regexp.Regexp), nil
+	}
+	r, err := regexp.Compile(strPattern)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(strPattern, r)
+	return r, nil
+}
+
+// validate field value by rules, nil pointer only checked by required rule
+func validateField(sf reflect.StructField, val reflect.Value, strColumn string, rules []validateRule) (errs ValidationErrors) {
+
+	isNil := val.Kind() == reflect.Ptr && val.IsNil()
+	if val.Kind() == reflect.Ptr && !isNil {
+		val = val.Elem()
+	}
+	var value interface{}
+	if !isNil && val.CanInterface() {
+		value = val.Interface()
+	}
+	for _, rule := range rules {
+		var strMsg string
+		if rule.Name == VALIDATE_RULE_REQUIRED {
+			if isNil || isZeroValue(val) {
+				strMsg = "is required"
+			}
+		} else if !isNil {
+			strMsg = validateValue(val, rule)
+		}
+		if strMsg != "" {
+			strRule := rule.Name
+			if rule.Param != "" {
+				strRule += "=" + rule.Param
+			}
+			errs = append(errs, &FieldError{Field: sf.Name, Column: strColumn, Rule: strRule, Value: value, Message: strMsg})
+		}
+	}
+	return
+}
+
+func isZeroValue(val reflect.Value) bool {
+	if d, ok := val.Interface().(Decimal); ok {
+		return d.IsZero()
+	}
+	return reflect.DeepEqual(val.Interface(), reflect.Zero(val.Type()).Interface())
+}
+
+// returns error message if failed
+func validateValue(val reflect.Value, rule validateRule) (strMsg string) {
+
+	switch rule.Name {
+	case VALIDATE_RULE_MIN, VALIDATE_RULE_MAX:
+		limit, err := strconv.ParseFloat(rule.Param, 64)
+		if err != nil {
+			return fmt.Sprintf("rule [%v] parameter [%v] is not a number", rule.Name, rule.Param)
+		}
+		n, strWhat, ok := getValidateNumber(val)
+		if !ok {
+			return
+		}
+		if rule.Name == VALIDATE_RULE_MIN && n < limit {
+			strMsg = fmt.Sprintf("%v must be at least %v", strWhat, rule.Param)
+		} else if rule.Name == VALIDATE_RULE_MAX && n > limit {
+			strMsg = fmt.Sprintf("%v must be at most %v", strWhat, rule.Param)
+		}
+	case VALIDATE_RULE_ONEOF:
+		strValue := fmt.Sprintf("%v", val.Interface())
+		for _, v := range strings.Fields(rule.Param) {
+			if v == strValue {
+				return
+			}
+		}
+		strMsg = fmt.Sprintf("must be one of [%v]", rule.Param)
+	case VALIDATE_RULE_EMAIL:
+		if val.Kind() == reflect.String && val.String() != "" && !regexEmail.MatchString(val.String()) {
+			strMsg = "is not a valid email"
+		}
+	case VALIDATE_RULE_REGEX:
+		r, err := getValidateRegex(rule.Param)
+		if err != nil {
+			return fmt.Sprintf("regex [%v] error [%v]", rule.Param, err.Error())
+		}
+		if !r.MatchString(fmt.Sprintf("%v", val.Interface())) {
+			strMsg = fmt.Sprintf("does not match [%v]", rule.Param)
+		}
+	default:
+		strMsg = fmt.Sprintf("unknown rule [%v]", rule.Name)
+	}
+	return
+}
+
+// string length (characters) or number value
+func getValidateNumber(val reflect.Value) (n float64, strWhat string, ok bool) {
+
+	if d, ok := val.Interface().(Decimal); ok {
+		return d.Float64(), "value", true
+	}
+	switch val.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(val.String())), "length", true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(val.Len()), "length", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(val.Int()), "value", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(val.Uint()), "value", true
+	case reflect.Float32, reflect.Float64:
+		return val.Float(), "value", true
+	}
+	return
+}
@@ -0,0 +1,89 @@
+package sqlca
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testMembersDDL = "CREATE TABLE members (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL DEFAULT '', " +
+	"email TEXT NOT NULL DEFAULT '', sex INTEGER NOT NULL DEFAULT 0, phone TEXT NOT NULL DEFAULT '')"
+
+type validateMember struct {
+	Id    int64  `db:"id"`
+	Name  string `db:"name" validate:"required,max=4"`
+	Email string `db:"email" validate:"email"`
+	Sex   int8   `db:"sex" validate:"oneof=1 2"`
+	Phone string `db:"phone" validate:"required,regex=^[0-9]{3,5}$"`
+}
+
+func TestParseValidateRules(t *testing.T) {
+
+	rules := parseValidateRules("required, max=32,regex=^[a-z,]+$")
+	expect := []validateRule{{Name: "required"}, {Name: "max", Param: "32"}, {Name: "regex", Param: "^[a-z,]+$"}}
+	if !reflect.DeepEqual(rules, expect) {
+		t.Errorf("%+v expected, got %+v", expect, rules)
+	}
+}
+
+func TestValidateModel(t *testing.T) {
+
+	e, done := newTestEngine(t, testMembersDDL)
+	defer done()
+
+	m := validateMember{Name: "张三李四五", Email: "bad", Sex: 3}
+	_, err := e.Model(&m).Table("members").Insert()
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("ValidationErrors expected, got %v", err)
+	}
+	var rules []string
+	for _, v := range errs {
+		rules = append(rules, v.Column+":"+v.Rule)
+	}
+	expect := []string{"name:max=4", "email:email", "sex:oneof=1 2", "phone:required", "phone:regex=^[0-9]{3,5}$"}
+	if !reflect.DeepEqual(rules, expect) {
+		t.Errorf("%v expected, got %v", expect, rules)
+	}
+	if n := countTestMembers(t, e); n != 0 {
+		t.Errorf("invalid model must not be inserted, got %v records", n)
+	}
+
+	m = validateMember{Name: "张三", Email: "a@b.cn", Sex: 1, Phone: "12345"}
+	if _, err = e.Model(&m).Table("members").Insert(); err != nil {
+		t.Fatalf("valid model insert error (%v)", err)
+	}
+
+	//slice model is validated by element
+	members := []*validateMember{{Name: "a", Sex: 2, Phone: "123"}, {Name: "b", Sex: 1}}
+	if _, err = e.Model(&members).Table("members").Insert(); err == nil {
+		t.Errorf("invalid element must fail")
+	} else if errs = err.(ValidationErrors); len(errs) != 2 || errs[0].Column != "phone" {
+		t.Errorf("required and regex of phone expected, got %v", err)
+	}
+	if n := countTestMembers(t, e); n != 1 {
+		t.Errorf("1 record expected, got %v", n)
+	}
+}
+
+func TestValidateUpdateSelected(t *testing.T) {
+
+	e, done := newTestEngine(t, testMembersDDL, "INSERT INTO members (name, sex, phone) VALUES ('a', 1, '123')")
+	defer done()
+
+	//only selected columns are validated by update
+	m := validateMember{Id: 1, Name: "b"}
+	if _, err := e.Model(&m).Table("members").Select("name").Update(); err != nil {
+		t.Fatalf("update selected valid column error (%v)", err)
+	}
+	m.Name = "toolong"
+	if _, err := e.Model(&m).Table("members").Select("name").Update(); err == nil {
+		t.Errorf("update invalid selected column must fail")
+	}
+}
+
+func countTestMembers(t *testing.T, e *Engine) (n int64) {
+	if _, err := e.Model(&n).QueryRaw("SELECT COUNT(*) FROM members"); err != nil {
+		t.Fatalf("count members error (%v)", err)
+	}
+	return
+}
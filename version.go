@@ -0,0 +1,97 @@
+package sqlca
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// returned by Update when model has a version column and no record matches the current version
+var ErrStaleVersion = errors.New("stale version, record was modified or deleted by others")
+
+func (e *Engine) setVersion(model interface{}) {
+	if model == nil {
+		return
+	}
+	for _, sf := range e.getSqlcaTagFields(reflect.TypeOf(model), SQLCA_TAG_VALUE_VERSION) {
+		if strName := e.getTagValue(sf); strName != "" {
+			e.strVersion = strName
+			return
+		}
+	}
+}
+
+func (e *Engine) isVersionColumn(strColumn string) bool {
+	return e.strVersion != "" && e.strVersion == strColumn
+}
+
+// optimistic locking is only for struct model update
+func (e *Engine) isVersionLock() bool {
+	return e.strVersion != "" && e.operType == OperType_Update && e.getModelType() == ModelType_Struct
+}
+
+// `version`='3'
+func (e *Engine) getVersionWhere() string {
+	return fmt.Sprintf("%v=%v", e.getQuoteColumnName(e.strVersion), e.getQuoteColumnValue(e.getModelValue(e.strVersion)))
+}
+
+// `version`=`version`+1
+func (e *Engine) getVersionUpdate() string {
+	strColumn := e.getQuoteColumnName(e.strVersion)
+	return fmt.Sprintf("%v=%v+1", strColumn, strColumn)
+}
+
+// write the bumped version back to model and dictionary
+func (e *Engine) bumpModelVersion() {
+
+	val := reflect.ValueOf(e.model)
+	for val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return
+	}
+	if v := e.bumpStructVersion(val); v != nil {
+		dict := make(map[string]interface{}, len(e.dict))
+		for k, vv := range e.dict {
+			dict[k] = vv //dictionary may be shared by builders
+		}
+		dict[e.strVersion] = v
+		e.dict = dict
+	}
+}
+
+func (e *Engine) bumpStructVersion(val reflect.Value) (value interface{}) {
+
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		fv := val.Field(i)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if sf.Anonymous && fv.Kind() == reflect.Struct {
+			if v := e.bumpStructVersion(fv); v != nil {
+				value = v
+			}
+			continue
+		}
+		if e.getTagValue(sf) != e.strVersion || !fv.CanSet() {
+			continue
+		}
+		switch fv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			fv.SetInt(fv.Int() + 1)
+			value = fv.Interface()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			fv.SetUint(fv.Uint() + 1)
+			value = fv.Interface()
+		default:
+			e.warnf("version column [%v] type [%v] not support", e.strVersion, fv.Type())
+		}
+	}
+	return
+}
@@ -0,0 +1,64 @@
+package sqlca
+
+import (
+	"strings"
+	"testing"
+)
+
+const testAccountsDDL = "CREATE TABLE accounts (id INTEGER PRIMARY KEY AUTOINCREMENT, balance INTEGER NOT NULL DEFAULT 0, version INTEGER NOT NULL DEFAULT 0)"
+
+type versionAccount struct {
+	Id      int64 `db:"id"`
+	Balance int64 `db:"balance"`
+	Version int32 `db:"version" sqlca:"version"`
+}
+
+func TestVersionLock(t *testing.T) {
+
+	e, done := newTestEngine(t, testAccountsDDL, "INSERT INTO accounts (balance, version) VALUES (10, 3)")
+	defer done()
+
+	a := versionAccount{Id: 1, Balance: 20, Version: 3}
+	stale := a //loaded before a is updated
+	if _, err := e.Model(&a).Table("accounts").Select("balance").Update(); err != nil {
+		t.Fatalf("update error (%v)", err)
+	}
+	if a.Version != 4 {
+		t.Errorf("model version 4 expected, got %v", a.Version)
+	}
+	var saved versionAccount
+	if _, err := e.Model(&saved).Table("accounts").Id(1).Query(); err != nil {
+		t.Fatalf("query error (%v)", err)
+	}
+	if saved.Balance != 20 || saved.Version != 4 {
+		t.Errorf("balance 20 version 4 expected, got %+v", saved)
+	}
+
+	stale.Balance = 30
+	if _, err := e.Model(&stale).Table("accounts").Select("balance").Update(); err != ErrStaleVersion {
+		t.Errorf("ErrStaleVersion expected, got %v", err)
+	}
+	if stale.Version != 3 {
+		t.Errorf("stale model version must not be bumped, got %v", stale.Version)
+	}
+}
+
+func TestVersionLockToSQL(t *testing.T) {
+
+	e, done := newTestEngine(t)
+	defer done()
+
+	a := versionAccount{Id: 1, Balance: 20, Version: 3}
+	strSql := normalizeSql(e.Model(&a).Table("accounts").Select("balance").ToSQL(OperType_Update))
+	if !strings.Contains(strSql, "`version`='3'") || !strings.Contains(strSql, "`version`=`version`+1") {
+		t.Errorf("version predicate and increment expected, got [%v]", strSql)
+	}
+	if a.Version != 3 {
+		t.Errorf("ToSQL must not change the model, got version %v", a.Version)
+	}
+
+	//no optimistic locking for query
+	if strSql = e.Model(&a).Table("accounts").Id(1).ToSQL(OperType_Query); strings.Contains(strSql, "`version`=") {
+		t.Errorf("version predicate is only for update, got [%v]", strSql)
+	}
+}